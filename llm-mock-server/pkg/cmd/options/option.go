@@ -1,11 +1,53 @@
 package options
 
 import (
+	"time"
+
 	"github.com/spf13/pflag"
 )
 
 type Option struct {
 	ServerPort uint32
+
+	// AwsAccessKeyId and AwsSecretAccessKey are the credentials used to verify AWS SigV4 signed requests.
+	AwsAccessKeyId     string
+	AwsSecretAccessKey string
+
+	// BaiduClientId and BaiduClientSecret are the credentials accepted by the Baidu OAuth token endpoint,
+	// which issues access tokens valid for BaiduAccessTokenTTL.
+	BaiduClientId       string
+	BaiduClientSecret   string
+	BaiduAccessTokenTTL time.Duration
+
+	// ZhipuApiKey is the "{id}.{secret}" API key used to verify the JWT of Zhipu AI requests.
+	ZhipuApiKey string
+
+	// SparkAppId, SparkApiKey and SparkApiSecret are the credentials used to verify iFlytek Spark WebSocket requests.
+	SparkAppId     string
+	SparkApiKey    string
+	SparkApiSecret string
+
+	// HunyuanSecretId and HunyuanSecretKey are the credentials used to verify TC3-HMAC-SHA256 signed Hunyuan requests.
+	HunyuanSecretId  string
+	HunyuanSecretKey string
+
+	// DifyAppType is the type of the Dify app (chat, agent or workflow) used when the API key does not encode one
+	// like "app-agent-xxx".
+	DifyAppType string
+
+	// CloudflareAccountId and CloudflareApiToken are the account ID and the API token accepted by Cloudflare
+	// Workers AI and AI Gateway requests.
+	CloudflareAccountId string
+	CloudflareApiToken  string
+
+	// VertexServiceAccountKeyFile is the JSON key file of the service account whose JWT assertions are accepted by the
+	// Vertex AI token endpoint. The key of a built-in mock service account is used if it is empty.
+	VertexServiceAccountKeyFile string
+	VertexAccessTokenTTL        time.Duration
+
+	// InlineThink makes the reasoning of OpenAI-compatible thinking models returned inline in the content wrapped in
+	// <think> tags instead of in reasoning_content.
+	InlineThink bool
 }
 
 func NewOption() *Option {
@@ -14,4 +56,21 @@ func NewOption() *Option {
 
 func (o *Option) AddFlags(flags *pflag.FlagSet) {
 	flags.Uint32Var(&o.ServerPort, "server-port", 3000, "The server port binds to.")
+	flags.StringVar(&o.AwsAccessKeyId, "aws-access-key-id", "mock-access-key-id", "The AWS access key ID accepted by the Bedrock provider.")
+	flags.StringVar(&o.AwsSecretAccessKey, "aws-secret-access-key", "mock-secret-access-key", "The AWS secret access key used to verify the SigV4 signature of Bedrock requests.")
+	flags.StringVar(&o.BaiduClientId, "baidu-client-id", "mock-client-id", "The client ID (API key) accepted by the Baidu OAuth token endpoint.")
+	flags.StringVar(&o.BaiduClientSecret, "baidu-client-secret", "mock-client-secret", "The client secret (secret key) accepted by the Baidu OAuth token endpoint.")
+	flags.DurationVar(&o.BaiduAccessTokenTTL, "baidu-access-token-ttl", 30*24*time.Hour, "The lifetime of the access tokens issued by the Baidu OAuth token endpoint.")
+	flags.StringVar(&o.ZhipuApiKey, "zhipu-api-key", "mock-id.mock-secret", "The API key in the form of \"{id}.{secret}\" accepted by the Zhipu AI provider.")
+	flags.StringVar(&o.SparkAppId, "spark-app-id", "mock-app-id", "The app ID accepted by the iFlytek Spark WebSocket provider.")
+	flags.StringVar(&o.SparkApiKey, "spark-api-key", "mock-api-key", "The API key accepted by the iFlytek Spark WebSocket provider.")
+	flags.StringVar(&o.SparkApiSecret, "spark-api-secret", "mock-api-secret", "The API secret used to verify the HMAC signature of iFlytek Spark WebSocket requests.")
+	flags.StringVar(&o.HunyuanSecretId, "hunyuan-secret-id", "mock-secret-id", "The Tencent Cloud SecretId accepted by the Hunyuan provider.")
+	flags.StringVar(&o.HunyuanSecretKey, "hunyuan-secret-key", "mock-secret-key", "The Tencent Cloud SecretKey used to verify the TC3-HMAC-SHA256 signature of Hunyuan requests.")
+	flags.StringVar(&o.DifyAppType, "dify-app-type", "chat", "The Dify app type (chat, agent or workflow) used when the API key is not in the form of \"app-{type}-xxx\".")
+	flags.StringVar(&o.CloudflareAccountId, "cloudflare-account-id", "mock-account-id", "The account ID accepted by the Cloudflare Workers AI and AI Gateway providers.")
+	flags.StringVar(&o.CloudflareApiToken, "cloudflare-api-token", "mock-api-token", "The API token accepted by the Cloudflare Workers AI and AI Gateway providers.")
+	flags.StringVar(&o.VertexServiceAccountKeyFile, "vertex-service-account-key-file", "", "The JSON key file of the service account accepted by the Vertex AI token endpoint. The built-in mock service account is used if empty.")
+	flags.DurationVar(&o.VertexAccessTokenTTL, "vertex-access-token-ttl", time.Hour, "The lifetime of the access tokens issued by the Vertex AI token endpoint.")
+	flags.BoolVar(&o.InlineThink, "inline-think", false, "Return the reasoning of OpenAI-compatible thinking models inline in the content wrapped in <think> tags instead of in reasoning_content.")
 }
@@ -10,8 +10,11 @@ import (
 	"llm-mock-server/pkg/cmd/options"
 	"llm-mock-server/pkg/log"
 	"llm-mock-server/pkg/middleware"
+	"llm-mock-server/pkg/provider"
 	"llm-mock-server/pkg/provider/chat"
 	"llm-mock-server/pkg/provider/embeddings"
+	"llm-mock-server/pkg/provider/image"
+	"llm-mock-server/pkg/provider/rerank"
 )
 
 func NewServerCommand() *cobra.Command {
@@ -38,11 +41,30 @@ func Run(option *options.Option) error {
 	server.Use(middleware.CORS())
 	middleware.StartLogger(server, option)
 
+	// Google APIs are authenticated the same way for chat completions and embeddings
+	googleAuth := provider.NewGoogleAuth(option.VertexServiceAccountKeyFile)
+
 	// Set up chat completion routes
-	chat.SetupRoutes(server)
+	chat.SetupRoutes(server, option, googleAuth)
 
 	// embeddings
 	server.POST("/v1/embeddings", embeddings.HandleEmbeddings)
+	server.POST("/openai/deployments/:deployment/embeddings", embeddings.HandleEmbeddings)
+	server.POST("/api/embed", embeddings.HandleEmbeddings)
+	server.POST("/v2/embed", embeddings.HandleEmbeddings)
+	server.POST("/api/v1/services/embeddings/text-embedding/text-embedding", embeddings.HandleEmbeddings)
+	provider.HandleModelActions(server, provider.GeminiModelRoutes, embeddings.GeminiActions,
+		googleAuth.RequireGeminiApiKey(embeddings.HandleEmbeddings))
+	provider.HandleModelActions(server, provider.VertexModelRoutes, embeddings.VertexActions,
+		googleAuth.RequireVertexAccessToken(embeddings.HandleEmbeddings))
+
+	// rerank
+	server.POST("/v1/rerank", rerank.HandleRerank)
+	server.POST("/v2/rerank", rerank.HandleRerank)
+	server.POST("/api/v1/services/rerank/text-rerank/text-rerank", rerank.HandleRerank)
+
+	// image generations
+	server.POST("/openai/deployments/:deployment/images/generations", image.HandleImageGenerations)
 
 	log.Infof("Starting server on port %d", option.ServerPort)
 	return server.Run(fmt.Sprintf(":%d", option.ServerPort))
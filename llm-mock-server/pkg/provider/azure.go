@@ -0,0 +1,118 @@
+package provider
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	azureDomainSuffix         = ".openai.azure.com"
+	azureDeploymentPathPrefix = "/openai/deployments/"
+
+	azureFilterSeveritySafe = "safe"
+)
+
+var (
+	azureSupportedApiVersions = map[string]bool{
+		"2023-05-15":         true,
+		"2023-06-01-preview": true,
+		"2023-12-01-preview": true,
+		"2024-02-01":         true,
+		"2024-02-15-preview": true,
+		"2024-03-01-preview": true,
+		"2024-04-01-preview": true,
+		"2024-05-01-preview": true,
+		"2024-06-01":         true,
+		"2024-07-01-preview": true,
+		"2024-08-01-preview": true,
+		"2024-09-01-preview": true,
+		"2024-10-01-preview": true,
+		"2024-10-21":         true,
+		"2024-12-01-preview": true,
+		"2025-01-01-preview": true,
+	}
+
+	azureFilterCategories = []string{"hate", "self_harm", "sexual", "violence"}
+)
+
+// IsAzureRequest checks if the request targets an Azure OpenAI deployment API ending with the given path suffix,
+// e.g. "/openai/deployments/{deployment}/chat/completions" for suffix "/chat/completions".
+func IsAzureRequest(ctx *gin.Context, pathSuffix string) bool {
+	path := ctx.Request.URL.Path
+	return strings.HasSuffix(ctx.Request.Host, azureDomainSuffix) &&
+		strings.HasPrefix(path, azureDeploymentPathPrefix) &&
+		strings.HasSuffix(path, pathSuffix)
+}
+
+// AzureDeployment returns the deployment name in the request path.
+func AzureDeployment(ctx *gin.Context) string {
+	deployment, _, _ := strings.Cut(strings.TrimPrefix(ctx.Request.URL.Path, azureDeploymentPathPrefix), "/")
+	return deployment
+}
+
+// ValidateAzureRequest checks the api-key header and the api-version query parameter of an Azure OpenAI request.
+// An Azure-style error response is sent and false is returned if the validation fails.
+func ValidateAzureRequest(ctx *gin.Context) bool {
+	// Azure OpenAI authenticates with the api-key header, or with a Microsoft Entra ID token in the Authorization header
+	if ctx.GetHeader("api-key") == "" && ctx.GetHeader("Authorization") == "" {
+		sendAzureErrorResponse(ctx, http.StatusUnauthorized, "401",
+			"Access denied due to invalid subscription key or wrong API endpoint. Make sure to provide a valid key for an active subscription and use a correct regional API endpoint for your resource.")
+		return false
+	}
+
+	apiVersion := ctx.Query("api-version")
+	if apiVersion == "" {
+		sendAzureErrorResponse(ctx, http.StatusNotFound, "404", "Resource not found")
+		return false
+	}
+	if !azureSupportedApiVersions[apiVersion] {
+		sendAzureErrorResponse(ctx, http.StatusBadRequest, "BadRequest", "API version not supported")
+		return false
+	}
+	return true
+}
+
+func sendAzureErrorResponse(ctx *gin.Context, statusCode int, code, message string) {
+	ctx.JSON(statusCode, gin.H{
+		"error": gin.H{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
+
+type AzureContentFilterResults map[string]AzureContentFilterResult
+
+type AzureContentFilterResult struct {
+	Filtered bool   `json:"filtered"`
+	Severity string `json:"severity"`
+}
+
+type AzurePromptFilterResult struct {
+	PromptIndex          int                       `json:"prompt_index"`
+	ContentFilterResults AzureContentFilterResults `json:"content_filter_results"`
+}
+
+// NewAzureContentFilterResults returns the content filter results of a content which passes all the filters.
+func NewAzureContentFilterResults() AzureContentFilterResults {
+	results := AzureContentFilterResults{}
+	for _, category := range azureFilterCategories {
+		results[category] = AzureContentFilterResult{
+			Filtered: false,
+			Severity: azureFilterSeveritySafe,
+		}
+	}
+	return results
+}
+
+// NewAzurePromptFilterResults returns the filter results of a single prompt which passes all the filters.
+func NewAzurePromptFilterResults() []AzurePromptFilterResult {
+	return []AzurePromptFilterResult{
+		{
+			PromptIndex:          0,
+			ContentFilterResults: NewAzureContentFilterResults(),
+		},
+	}
+}
@@ -0,0 +1,56 @@
+package chat
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+)
+
+const (
+	awsEventStreamContentType = "application/vnd.amazon.eventstream"
+
+	awsEventStreamHeaderValueTypeString = 7
+	// awsEventStreamPreludeLength is the length of the total length, the headers length and the prelude CRC.
+	awsEventStreamPreludeLength = 12
+	awsEventStreamCrcLength     = 4
+)
+
+type awsEventStreamHeader struct {
+	Name  string
+	Value string
+}
+
+// encodeAwsEventStreamMessage encodes a message in the binary application/vnd.amazon.eventstream framing:
+//
+//	total length (4) | headers length (4) | prelude crc (4) | headers | payload | message crc (4)
+//
+// All integers are big-endian, and both CRCs are CRC32 (IEEE) checksums of all the preceding bytes.
+func encodeAwsEventStreamMessage(headers []awsEventStreamHeader, payload []byte) []byte {
+	var headersBuf bytes.Buffer
+	for _, header := range headers {
+		headersBuf.WriteByte(byte(len(header.Name)))
+		headersBuf.WriteString(header.Name)
+		headersBuf.WriteByte(awsEventStreamHeaderValueTypeString)
+		_ = binary.Write(&headersBuf, binary.BigEndian, uint16(len(header.Value)))
+		headersBuf.WriteString(header.Value)
+	}
+
+	totalLength := awsEventStreamPreludeLength + headersBuf.Len() + len(payload) + awsEventStreamCrcLength
+	var message bytes.Buffer
+	_ = binary.Write(&message, binary.BigEndian, uint32(totalLength))
+	_ = binary.Write(&message, binary.BigEndian, uint32(headersBuf.Len()))
+	_ = binary.Write(&message, binary.BigEndian, crc32.ChecksumIEEE(message.Bytes()))
+	message.Write(headersBuf.Bytes())
+	message.Write(payload)
+	_ = binary.Write(&message, binary.BigEndian, crc32.ChecksumIEEE(message.Bytes()))
+	return message.Bytes()
+}
+
+// encodeAwsEvent encodes a JSON event of the given type.
+func encodeAwsEvent(eventType string, payload []byte) []byte {
+	return encodeAwsEventStreamMessage([]awsEventStreamHeader{
+		{Name: ":event-type", Value: eventType},
+		{Name: ":content-type", Value: "application/json"},
+		{Name: ":message-type", Value: "event"},
+	}, payload)
+}
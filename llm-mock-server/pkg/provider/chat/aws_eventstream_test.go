@@ -0,0 +1,113 @@
+package chat
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"testing"
+)
+
+// decodeAwsEventStreamMessage decodes a single message the way the AWS SDKs do, checking the lengths and both CRCs.
+func decodeAwsEventStreamMessage(message []byte) ([]awsEventStreamHeader, []byte, error) {
+	if len(message) < awsEventStreamPreludeLength+awsEventStreamCrcLength {
+		return nil, nil, fmt.Errorf("message is too short")
+	}
+	totalLength := binary.BigEndian.Uint32(message[0:4])
+	headersLength := binary.BigEndian.Uint32(message[4:8])
+	if int(totalLength) != len(message) {
+		return nil, nil, fmt.Errorf("total length %d does not match message length %d", totalLength, len(message))
+	}
+	if binary.BigEndian.Uint32(message[8:12]) != crc32.ChecksumIEEE(message[0:8]) {
+		return nil, nil, fmt.Errorf("prelude checksum mismatch")
+	}
+	crcOffset := len(message) - awsEventStreamCrcLength
+	if binary.BigEndian.Uint32(message[crcOffset:]) != crc32.ChecksumIEEE(message[:crcOffset]) {
+		return nil, nil, fmt.Errorf("message checksum mismatch")
+	}
+
+	var headers []awsEventStreamHeader
+	headersBuf := message[awsEventStreamPreludeLength : awsEventStreamPreludeLength+headersLength]
+	for len(headersBuf) > 0 {
+		nameLength := int(headersBuf[0])
+		name := string(headersBuf[1 : 1+nameLength])
+		headersBuf = headersBuf[1+nameLength:]
+		if headersBuf[0] != awsEventStreamHeaderValueTypeString {
+			return nil, nil, fmt.Errorf("unexpected header value type %d", headersBuf[0])
+		}
+		valueLength := int(binary.BigEndian.Uint16(headersBuf[1:3]))
+		headers = append(headers, awsEventStreamHeader{Name: name, Value: string(headersBuf[3 : 3+valueLength])})
+		headersBuf = headersBuf[3+valueLength:]
+	}
+	return headers, message[awsEventStreamPreludeLength+headersLength : crcOffset], nil
+}
+
+func TestEncodeAwsEventStreamMessage(t *testing.T) {
+	payload := []byte(`{"contentBlockIndex":0,"delta":{"text":"Hello"}}`)
+
+	tests := []struct {
+		name    string
+		tamper  func(message []byte)
+		wantErr string
+	}{
+		{
+			name:   "valid message",
+			tamper: func(message []byte) {},
+		},
+		{
+			name:    "tampered prelude",
+			tamper:  func(message []byte) { message[7]++ },
+			wantErr: "prelude checksum mismatch",
+		},
+		{
+			name:    "tampered payload",
+			tamper:  func(message []byte) { message[len(message)-awsEventStreamCrcLength-1]++ },
+			wantErr: "message checksum mismatch",
+		},
+		{
+			name:    "tampered message checksum",
+			tamper:  func(message []byte) { message[len(message)-1]++ },
+			wantErr: "message checksum mismatch",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := encodeAwsEvent("contentBlockDelta", payload)
+			tt.tamper(message)
+
+			headers, decodedPayload, err := decodeAwsEventStreamMessage(message)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expectedHeaders := []awsEventStreamHeader{
+				{Name: ":event-type", Value: "contentBlockDelta"},
+				{Name: ":content-type", Value: "application/json"},
+				{Name: ":message-type", Value: "event"},
+			}
+			if fmt.Sprint(headers) != fmt.Sprint(expectedHeaders) {
+				t.Errorf("headers = %v, want %v", headers, expectedHeaders)
+			}
+			if !bytes.Equal(decodedPayload, payload) {
+				t.Errorf("payload = %s, want %s", decodedPayload, payload)
+			}
+		})
+	}
+}
+
+func TestEncodeAwsEventStreamMessageKnownFrame(t *testing.T) {
+	// An empty message is only made of the prelude and the message CRC
+	expected := []byte{
+		0x00, 0x00, 0x00, 0x10, 0x00, 0x00, 0x00, 0x00,
+		0x05, 0xc2, 0x48, 0xeb, 0x7d, 0x98, 0xc8, 0xff,
+	}
+	if message := encodeAwsEventStreamMessage(nil, nil); !bytes.Equal(message, expected) {
+		t.Errorf("message = %x, want %x", message, expected)
+	}
+}
@@ -0,0 +1,207 @@
+package chat
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	awsSigV4Algorithm     = "AWS4-HMAC-SHA256"
+	awsSigV4TimeFormat    = "20060102T150405Z"
+	awsSigV4ScopeTerminal = "aws4_request"
+	awsSigV4MaxClockSkew  = 15 * time.Minute
+
+	awsHeaderAmzDate       = "X-Amz-Date"
+	awsHeaderContentSha256 = "X-Amz-Content-Sha256"
+	// awsUnsignedPayload is the payload hash of requests whose body is not signed.
+	awsUnsignedPayload = "UNSIGNED-PAYLOAD"
+)
+
+// awsSigV4Error is returned when a request fails the SigV4 verification.
+// ErrorType is the AWS exception name which is sent back in the x-amzn-ErrorType header.
+type awsSigV4Error struct {
+	ErrorType string
+	Message   string
+}
+
+func (e *awsSigV4Error) Error() string {
+	return e.Message
+}
+
+// awsSigV4Credential is the parsed Authorization header of a SigV4 signed request.
+type awsSigV4Credential struct {
+	AccessKeyId   string
+	Date          string
+	Region        string
+	Service       string
+	SignedHeaders []string
+	Signature     string
+}
+
+func (c *awsSigV4Credential) scope() string {
+	return strings.Join([]string{c.Date, c.Region, c.Service, awsSigV4ScopeTerminal}, "/")
+}
+
+// parseAwsSigV4Authorization parses an Authorization header like
+// "AWS4-HMAC-SHA256 Credential=AKID/20240101/us-east-1/bedrock/aws4_request, SignedHeaders=host;x-amz-date, Signature=...".
+func parseAwsSigV4Authorization(authorization string) (*awsSigV4Credential, error) {
+	algorithm, params, found := strings.Cut(authorization, " ")
+	if !found || algorithm != awsSigV4Algorithm {
+		return nil, fmt.Errorf("unsupported authorization algorithm")
+	}
+	credential := &awsSigV4Credential{}
+	for _, param := range strings.Split(params, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		switch key {
+		case "Credential":
+			parts := strings.Split(value, "/")
+			if len(parts) != 5 || parts[4] != awsSigV4ScopeTerminal {
+				return nil, fmt.Errorf("malformed credential scope")
+			}
+			credential.AccessKeyId = parts[0]
+			credential.Date = parts[1]
+			credential.Region = parts[2]
+			credential.Service = parts[3]
+		case "SignedHeaders":
+			credential.SignedHeaders = strings.Split(value, ";")
+		case "Signature":
+			credential.Signature = value
+		}
+	}
+	if credential.AccessKeyId == "" || len(credential.SignedHeaders) == 0 || credential.Signature == "" {
+		return nil, fmt.Errorf("incomplete authorization header")
+	}
+	return credential, nil
+}
+
+// verifyAwsSigV4 verifies the SigV4 signature of the request against the given credentials.
+func verifyAwsSigV4(request *http.Request, body []byte, accessKeyId, secretAccessKey string) error {
+	return verifyAwsSigV4At(request, body, accessKeyId, secretAccessKey, time.Now())
+}
+
+// verifyAwsSigV4At verifies the SigV4 signature of the request as of the given time.
+func verifyAwsSigV4At(request *http.Request, body []byte, accessKeyId, secretAccessKey string, now time.Time) error {
+	authorization := request.Header.Get("Authorization")
+	if authorization == "" {
+		return &awsSigV4Error{ErrorType: "MissingAuthenticationTokenException", Message: "Missing Authentication Token"}
+	}
+	credential, err := parseAwsSigV4Authorization(authorization)
+	if err != nil {
+		return &awsSigV4Error{ErrorType: "IncompleteSignatureException", Message: fmt.Sprintf("Authorization header requires 'Credential', 'Signature' and 'SignedHeaders' parameters: %v", err)}
+	}
+	if credential.AccessKeyId != accessKeyId {
+		return &awsSigV4Error{ErrorType: "UnrecognizedClientException", Message: "The security token included in the request is invalid."}
+	}
+
+	amzDate := request.Header.Get(awsHeaderAmzDate)
+	signTime, err := time.Parse(awsSigV4TimeFormat, amzDate)
+	if err != nil || !strings.HasPrefix(amzDate, credential.Date) {
+		return &awsSigV4Error{ErrorType: "IncompleteSignatureException", Message: "Authorization header requires existence of either a 'X-Amz-Date' or a 'Date' header."}
+	}
+	if skew := now.Sub(signTime); skew > awsSigV4MaxClockSkew || skew < -awsSigV4MaxClockSkew {
+		return &awsSigV4Error{ErrorType: "InvalidSignatureException", Message: fmt.Sprintf("Signature expired: %s is now earlier than %s", amzDate, now.UTC().Add(-awsSigV4MaxClockSkew).Format(awsSigV4TimeFormat))}
+	}
+
+	// The signed payload hash must be the hash of the body actually sent, unless the payload is not signed
+	payloadHash := request.Header.Get(awsHeaderContentSha256)
+	if payloadHash == "" {
+		payloadHash = sha256Hex(body)
+	} else if payloadHash != awsUnsignedPayload && payloadHash != sha256Hex(body) {
+		return &awsSigV4Error{ErrorType: "InvalidSignatureException", Message: "The provided 'x-amz-content-sha256' header does not match what was computed."}
+	}
+	signingKey := awsSigV4SigningKey(secretAccessKey, credential)
+	// Clients differ in whether the path is URI-encoded once or twice in the canonical request,
+	// so both forms are accepted.
+	escapedPath := request.URL.EscapedPath()
+	for _, canonicalUri := range []string{awsUriEncode(escapedPath, false), escapedPath} {
+		canonicalRequest := strings.Join([]string{
+			request.Method,
+			canonicalUri,
+			awsCanonicalQueryString(request.URL.Query()),
+			awsCanonicalHeaders(request, credential.SignedHeaders),
+			strings.Join(credential.SignedHeaders, ";"),
+			payloadHash,
+		}, "\n")
+		stringToSign := strings.Join([]string{
+			awsSigV4Algorithm,
+			amzDate,
+			credential.scope(),
+			sha256Hex([]byte(canonicalRequest)),
+		}, "\n")
+		signature := hex.EncodeToString(hmacSha256(signingKey, stringToSign))
+		if hmac.Equal([]byte(signature), []byte(credential.Signature)) {
+			return nil
+		}
+	}
+	return &awsSigV4Error{ErrorType: "InvalidSignatureException", Message: "The request signature we calculated does not match the signature you provided. Check your AWS Secret Access Key and signing method. Consult the service documentation for details."}
+}
+
+func awsSigV4SigningKey(secretAccessKey string, credential *awsSigV4Credential) []byte {
+	key := hmacSha256([]byte("AWS4"+secretAccessKey), credential.Date)
+	key = hmacSha256(key, credential.Region)
+	key = hmacSha256(key, credential.Service)
+	return hmacSha256(key, awsSigV4ScopeTerminal)
+}
+
+func awsCanonicalQueryString(query url.Values) string {
+	var pairs []string
+	for key, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, awsUriEncode(key, true)+"="+awsUriEncode(value, true))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// awsCanonicalHeaders returns the canonical headers block, which ends with a newline followed by the
+// blank line separating it from the signed headers list.
+func awsCanonicalHeaders(request *http.Request, signedHeaders []string) string {
+	var builder strings.Builder
+	for _, name := range signedHeaders {
+		var value string
+		if name == "host" {
+			value = request.Host
+		} else {
+			value = strings.Join(request.Header.Values(name), ",")
+		}
+		builder.WriteString(name)
+		builder.WriteString(":")
+		builder.WriteString(strings.Join(strings.Fields(value), " "))
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+// awsUriEncode encodes every byte except the unreserved characters, and "/" unless encodeSlash is set.
+func awsUriEncode(str string, encodeSlash bool) string {
+	var builder strings.Builder
+	for i := 0; i < len(str); i++ {
+		c := str[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !encodeSlash) {
+			builder.WriteByte(c)
+		} else {
+			builder.WriteString(fmt.Sprintf("%%%02X", c))
+		}
+	}
+	return builder.String()
+}
+
+func hmacSha256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
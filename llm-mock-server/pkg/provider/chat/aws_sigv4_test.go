@@ -0,0 +1,194 @@
+package chat
+
+import (
+	"bytes"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testAwsAccessKeyId     = "AKIDEXAMPLE"
+	testAwsSecretAccessKey = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+
+	// testAwsPostVanillaAuthorization is the signature of the post-vanilla request of the AWS SigV4 test suite.
+	testAwsPostVanillaAuthorization = "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b"
+
+	// testAwsBedrockUrl is a Bedrock URL whose model ID contains a colon, which the SDKs send percent-encoded and
+	// encode again in the canonical URI, i.e. "v1%253A0".
+	testAwsBedrockUrl  = "https://bedrock-runtime.us-east-1.amazonaws.com/model/anthropic.claude-3-haiku-20240307-v1%3A0/converse"
+	testAwsBedrockBody = `{"messages":[{"role":"user","content":[{"text":"Hello"}]}]}`
+	// testAwsBedrockAuthorization and testAwsBedrockUnsignedAuthorization are the signatures of the Bedrock request
+	// with the payload hash of testAwsBedrockBody and with UNSIGNED-PAYLOAD, computed by an independent signer.
+	testAwsBedrockAuthorization         = "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20240101/us-east-1/bedrock/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=a92fd573ac5256a3fad516f3ab22ab7768f114a13dea95e03acc7c5adf445939"
+	testAwsBedrockUnsignedAuthorization = "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20240101/us-east-1/bedrock/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=1da448a5dd55d727573a3ab7770643bf7144490b0301ddece059bb9b37e8553d"
+	testAwsBedrockBodyHash              = "7421da8d1a0949a481724fee62d5886aacde03836bf58248adb73e8b61ac0d54"
+)
+
+// tamperLastChar replaces the last hex digit of a signature with another one.
+func tamperLastChar(signature string) string {
+	last := "0"
+	if strings.HasSuffix(signature, last) {
+		last = "1"
+	}
+	return signature[:len(signature)-1] + last
+}
+
+func TestAwsSigV4SigningKey(t *testing.T) {
+	// The example of deriving a signing key in the AWS documentation
+	credential := &awsSigV4Credential{Date: "20120215", Region: "us-east-1", Service: "iam"}
+	signingKey := hex.EncodeToString(awsSigV4SigningKey(testAwsSecretAccessKey, credential))
+	if expected := "f4780e2d9f65fa895f9c67b32ce1baf0b0d8a43505a000a1a9e090d414db404d"; signingKey != expected {
+		t.Errorf("signing key = %s, want %s", signingKey, expected)
+	}
+}
+
+func TestVerifyAwsSigV4(t *testing.T) {
+	postVanillaTime := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	bedrockTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		url           string
+		headers       map[string]string
+		body          string
+		now           time.Time
+		accessKeyId   string
+		wantErrorType string
+		wantMessage   string
+	}{
+		{
+			name: "post-vanilla",
+			url:  "https://example.amazonaws.com/",
+			headers: map[string]string{
+				"X-Amz-Date":    "20150830T123600Z",
+				"Authorization": testAwsPostVanillaAuthorization,
+			},
+			now: postVanillaTime,
+		},
+		{
+			name: "post-vanilla with tampered signature",
+			url:  "https://example.amazonaws.com/",
+			headers: map[string]string{
+				"X-Amz-Date":    "20150830T123600Z",
+				"Authorization": tamperLastChar(testAwsPostVanillaAuthorization),
+			},
+			now:           postVanillaTime,
+			wantErrorType: "InvalidSignatureException",
+			wantMessage:   "does not match the signature you provided",
+		},
+		{
+			name: "post-vanilla with tampered body",
+			url:  "https://example.amazonaws.com/",
+			headers: map[string]string{
+				"X-Amz-Date":    "20150830T123600Z",
+				"Authorization": testAwsPostVanillaAuthorization,
+			},
+			body:          "tampered",
+			now:           postVanillaTime,
+			wantErrorType: "InvalidSignatureException",
+			wantMessage:   "does not match the signature you provided",
+		},
+		{
+			name: "post-vanilla with expired timestamp",
+			url:  "https://example.amazonaws.com/",
+			headers: map[string]string{
+				"X-Amz-Date":    "20150830T123600Z",
+				"Authorization": testAwsPostVanillaAuthorization,
+			},
+			now:           postVanillaTime.Add(time.Hour),
+			wantErrorType: "InvalidSignatureException",
+			wantMessage:   "Signature expired",
+		},
+		{
+			name: "bedrock model ID with colon",
+			url:  testAwsBedrockUrl,
+			headers: map[string]string{
+				"X-Amz-Date":           "20240101T000000Z",
+				"X-Amz-Content-Sha256": testAwsBedrockBodyHash,
+				"Authorization":        testAwsBedrockAuthorization,
+			},
+			body: testAwsBedrockBody,
+			now:  bedrockTime,
+		},
+		{
+			name: "bedrock with tampered body",
+			url:  testAwsBedrockUrl,
+			headers: map[string]string{
+				"X-Amz-Date":           "20240101T000000Z",
+				"X-Amz-Content-Sha256": testAwsBedrockBodyHash,
+				"Authorization":        testAwsBedrockAuthorization,
+			},
+			body:          `{"messages":[{"role":"user","content":[{"text":"Tampered"}]}]}`,
+			now:           bedrockTime,
+			wantErrorType: "InvalidSignatureException",
+			wantMessage:   "x-amz-content-sha256",
+		},
+		{
+			name: "bedrock with unsigned payload",
+			url:  testAwsBedrockUrl,
+			headers: map[string]string{
+				"X-Amz-Date":           "20240101T000000Z",
+				"X-Amz-Content-Sha256": awsUnsignedPayload,
+				"Authorization":        testAwsBedrockUnsignedAuthorization,
+			},
+			body: `{"messages":[{"role":"user","content":[{"text":"Any body"}]}]}`,
+			now:  bedrockTime,
+		},
+		{
+			name: "bedrock with unknown access key",
+			url:  testAwsBedrockUrl,
+			headers: map[string]string{
+				"X-Amz-Date":           "20240101T000000Z",
+				"X-Amz-Content-Sha256": testAwsBedrockBodyHash,
+				"Authorization":        testAwsBedrockAuthorization,
+			},
+			body:          testAwsBedrockBody,
+			now:           bedrockTime,
+			accessKeyId:   "AKIDOTHER",
+			wantErrorType: "UnrecognizedClientException",
+		},
+		{
+			name:          "missing authorization",
+			url:           testAwsBedrockUrl,
+			headers:       map[string]string{"X-Amz-Date": "20240101T000000Z"},
+			body:          testAwsBedrockBody,
+			now:           bedrockTime,
+			wantErrorType: "MissingAuthenticationTokenException",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, tt.url, bytes.NewReader([]byte(tt.body)))
+			for name, value := range tt.headers {
+				request.Header.Set(name, value)
+			}
+			accessKeyId := tt.accessKeyId
+			if accessKeyId == "" {
+				accessKeyId = testAwsAccessKeyId
+			}
+
+			err := verifyAwsSigV4At(request, []byte(tt.body), accessKeyId, testAwsSecretAccessKey, tt.now)
+			if tt.wantErrorType == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			sigV4Err, ok := err.(*awsSigV4Error)
+			if !ok {
+				t.Fatalf("error = %v, want %s", err, tt.wantErrorType)
+			}
+			if sigV4Err.ErrorType != tt.wantErrorType {
+				t.Errorf("error type = %s, want %s", sigV4Err.ErrorType, tt.wantErrorType)
+			}
+			if !strings.Contains(sigV4Err.Message, tt.wantMessage) {
+				t.Errorf("error message = %q, want it to contain %q", sigV4Err.Message, tt.wantMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package chat
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"llm-mock-server/pkg/provider"
+	"llm-mock-server/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const azureChatCompletionPathSuffix = "/chat/completions"
+
+type azureProvider struct{}
+
+func (p *azureProvider) ShouldHandleRequest(ctx *gin.Context) bool {
+	return provider.IsAzureRequest(ctx, azureChatCompletionPathSuffix)
+}
+
+func (p *azureProvider) HandleChatCompletions(ctx *gin.Context) {
+	if !provider.ValidateAzureRequest(ctx) {
+		return
+	}
+
+	// Bind request body
+	var chatRequest chatCompletionRequest
+	if err := ctx.ShouldBindJSON(&chatRequest); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate request body, the model is optional since it is determined by the deployment
+	if err := utils.Validate.StructExcept(chatRequest, "Model"); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		for _, fieldError := range validationErrors {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fieldError.Error()})
+			return
+		}
+	}
+	if chatRequest.Model == "" {
+		chatRequest.Model = provider.AzureDeployment(ctx)
+	}
+
+	prompt := ""
+	if chatRequest.Messages[len(chatRequest.Messages)-1].IsStringContent() {
+		prompt = chatRequest.Messages[len(chatRequest.Messages)-1].StringContent()
+	}
+	response := prompt2Response(prompt)
+
+	if chatRequest.Stream {
+		p.handleStreamResponse(ctx, chatRequest, response)
+	} else {
+		p.handleNonStreamResponse(ctx, chatRequest, response)
+	}
+}
+
+func (p *azureProvider) handleStreamResponse(ctx *gin.Context, chatRequest chatCompletionRequest, response string) {
+	utils.SetEventStreamHeaders(ctx)
+	dataChan := make(chan string)
+	stopChan := make(chan bool, 1)
+	go func() {
+		// Azure sends the prompt filter results in a leading chunk without choices
+		promptFilterResponse := chatCompletionResponse{
+			Choices:             []chatCompletionChoice{},
+			PromptFilterResults: provider.NewAzurePromptFilterResults(),
+		}
+		jsonStr, _ := json.Marshal(promptFilterResponse)
+		dataChan <- string(jsonStr)
+
+		streamResponse := chatCompletionResponse{
+			Id:      completionMockId,
+			Object:  objectChatCompletionChunk,
+			Created: completionMockCreated,
+			Model:   chatRequest.Model,
+		}
+		streamResponseChoice := chatCompletionChoice{
+			Delta:                &chatMessage{},
+			ContentFilterResults: provider.NewAzureContentFilterResults(),
+		}
+		responseRunes := []rune(response)
+		for i, s := range responseRunes {
+			streamResponseChoice.Delta.Content = string(s)
+			if i == len(responseRunes)-1 {
+				streamResponseChoice.FinishReason = ptr(stopReason)
+			}
+			streamResponse.Choices = []chatCompletionChoice{streamResponseChoice}
+			jsonStr, _ := json.Marshal(streamResponse)
+			dataChan <- string(jsonStr)
+
+			// Simulate response delay
+			time.Sleep(200 * time.Millisecond)
+		}
+		stopChan <- true
+	}()
+
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case data := <-dataChan:
+			ctx.Render(-1, streamEvent{Data: "data: " + data})
+			return true
+		case <-stopChan:
+			ctx.Render(-1, streamEvent{Data: "data: [DONE]"})
+			return false
+		}
+	})
+}
+
+func (p *azureProvider) handleNonStreamResponse(ctx *gin.Context, chatRequest chatCompletionRequest, response string) {
+	completion := createChatCompletionResponse(chatRequest.Model, response)
+	completion.PromptFilterResults = provider.NewAzurePromptFilterResults()
+	for i := range completion.Choices {
+		completion.Choices[i].ContentFilterResults = provider.NewAzureContentFilterResults()
+	}
+	ctx.JSON(http.StatusOK, completion)
+}
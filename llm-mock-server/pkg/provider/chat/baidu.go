@@ -0,0 +1,236 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"llm-mock-server/pkg/provider"
+	"llm-mock-server/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	baiduDomain = "aip.baidubce.com"
+	// baiduChatPathPrefix is the prefix of the legacy wenxinworkshop API paths, which end with the model endpoint name.
+	baiduChatPathPrefix = "/rpc/2.0/ai_custom/v1/wenxinworkshop/chat/"
+	baiduOAuthTokenPath = "/oauth/2.0/token"
+
+	baiduMockId                     = "as-llm-mock"
+	baiduAccessTokenPrefix          = "24."
+	baiduFinishReasonNormal         = "normal"
+	baiduGrantTypeClientCredentials = "client_credentials"
+
+	baiduErrorCodeAccessTokenInvalid = 110
+	baiduErrorCodeAccessTokenExpired = 111
+	baiduErrorCodeInvalidParam       = 336003
+)
+
+type baiduProvider struct {
+	clientId       string
+	clientSecret   string
+	accessTokenTTL time.Duration
+	accessTokens   *provider.AccessTokenStore
+}
+
+func (p *baiduProvider) ShouldHandleRequest(ctx *gin.Context) bool {
+	context, _ := getRequestContext(ctx)
+	return context.Host == baiduDomain && strings.HasPrefix(context.Path, baiduChatPathPrefix)
+}
+
+func (p *baiduProvider) registerRoutes(server *gin.Engine) {
+	server.GET(baiduOAuthTokenPath, p.handleOAuthToken)
+	server.POST(baiduOAuthTokenPath, p.handleOAuthToken)
+}
+
+// handleOAuthToken issues an access token in exchange for the client ID and secret.
+func (p *baiduProvider) handleOAuthToken(ctx *gin.Context) {
+	// The bare token path would match the token endpoints of other services
+	if ctx.Request.Host != baiduDomain {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+		return
+	}
+	if ctx.Query("grant_type") != baiduGrantTypeClientCredentials {
+		ctx.JSON(http.StatusBadRequest, baiduOAuthErrorResp{
+			Error:            "unsupported_grant_type",
+			ErrorDescription: "The authorization grant type is not supported",
+		})
+		return
+	}
+	if ctx.Query("client_id") != p.clientId {
+		ctx.JSON(http.StatusUnauthorized, baiduOAuthErrorResp{
+			Error:            "invalid_client",
+			ErrorDescription: "unknown client id",
+		})
+		return
+	}
+	if ctx.Query("client_secret") != p.clientSecret {
+		ctx.JSON(http.StatusUnauthorized, baiduOAuthErrorResp{
+			Error:            "invalid_client",
+			ErrorDescription: "Client authentication failed",
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, baiduOAuthTokenResp{
+		RefreshToken:  provider.RandomToken(baiduAccessTokenPrefix),
+		ExpiresIn:     int64(p.accessTokenTTL.Seconds()),
+		SessionKey:    baiduMockId,
+		AccessToken:   p.accessTokens.Issue(baiduAccessTokenPrefix, p.accessTokenTTL),
+		Scope:         "public brain_all_scope",
+		SessionSecret: baiduMockId,
+	})
+}
+
+func (p *baiduProvider) HandleChatCompletions(ctx *gin.Context) {
+	// Validate access token
+	found, expired := p.accessTokens.Lookup(ctx.Query("access_token"))
+	if !found {
+		p.sendErrorResponse(ctx, baiduErrorCodeAccessTokenInvalid, "Access token invalid or no longer valid")
+		return
+	}
+	if expired {
+		p.sendErrorResponse(ctx, baiduErrorCodeAccessTokenExpired, "Access token expired")
+		return
+	}
+
+	// Bind request body
+	var chatRequest baiduChatRequest
+	if err := ctx.ShouldBindJSON(&chatRequest); err != nil {
+		p.sendErrorResponse(ctx, baiduErrorCodeInvalidParam, fmt.Sprintf("the request body is invalid: %v", err.Error()))
+		return
+	}
+
+	// Validate request body
+	if err := utils.Validate.Struct(chatRequest); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		for _, fieldError := range validationErrors {
+			p.sendErrorResponse(ctx, baiduErrorCodeInvalidParam, fmt.Sprintf("the messages parameter is invalid: %v", fieldError.Error()))
+			return
+		}
+	}
+
+	response := prompt2Response(chatRequest.Messages[len(chatRequest.Messages)-1].Content)
+
+	if chatRequest.Stream {
+		p.handleStreamResponse(ctx, response)
+	} else {
+		p.handleNonStreamResponse(ctx, response)
+	}
+}
+
+// sendErrorResponse sends an error response, which is returned with HTTP status 200 by Baidu.
+func (p *baiduProvider) sendErrorResponse(ctx *gin.Context, errorCode int, errorMsg string) {
+	ctx.JSON(http.StatusOK, baiduErrorResp{
+		ErrorCode: errorCode,
+		ErrorMsg:  errorMsg,
+	})
+}
+
+func (p *baiduProvider) handleStreamResponse(ctx *gin.Context, response string) {
+	utils.SetEventStreamHeaders(ctx)
+	dataChan := make(chan string)
+	stopChan := make(chan bool, 1)
+
+	go func() {
+		send := func(result string, sentenceId int, isEnd bool) {
+			streamResponse := p.createChatResponse(result)
+			streamResponse.SentenceId = ptr(sentenceId)
+			streamResponse.IsEnd = ptr(isEnd)
+			jsonStr, _ := json.Marshal(streamResponse)
+			dataChan <- string(jsonStr)
+		}
+
+		sentenceId := 0
+		for _, s := range response {
+			send(string(s), sentenceId, false)
+			sentenceId++
+
+			// Simulate response delay
+			time.Sleep(200 * time.Millisecond)
+		}
+
+		// Clients read until is_end, so the last chunk is sent even if the response is empty
+		send("", sentenceId, true)
+		stopChan <- true
+	}()
+
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case data := <-dataChan:
+			ctx.Render(-1, streamEvent{Data: "data: " + data})
+			return true
+		case <-stopChan:
+			return false
+		}
+	})
+}
+
+func (p *baiduProvider) handleNonStreamResponse(ctx *gin.Context, response string) {
+	ctx.JSON(http.StatusOK, p.createChatResponse(response))
+}
+
+func (p *baiduProvider) createChatResponse(response string) baiduChatResponse {
+	return baiduChatResponse{
+		Id:           baiduMockId,
+		Object:       objectChatCompletion,
+		Created:      completionMockCreated,
+		Result:       response,
+		FinishReason: baiduFinishReasonNormal,
+		Usage:        completionMockUsage,
+	}
+}
+
+type baiduOAuthTokenResp struct {
+	RefreshToken  string `json:"refresh_token"`
+	ExpiresIn     int64  `json:"expires_in"`
+	SessionKey    string `json:"session_key"`
+	AccessToken   string `json:"access_token"`
+	Scope         string `json:"scope"`
+	SessionSecret string `json:"session_secret"`
+}
+
+type baiduOAuthErrorResp struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+type baiduChatRequest struct {
+	Messages        []baiduMessage `json:"messages" validate:"required,min=1"`
+	Stream          bool           `json:"stream,omitempty"`
+	Temperature     float64        `json:"temperature,omitempty"`
+	TopP            float64        `json:"top_p,omitempty"`
+	PenaltyScore    float64        `json:"penalty_score,omitempty"`
+	System          string         `json:"system,omitempty"`
+	Stop            []string       `json:"stop,omitempty"`
+	MaxOutputTokens int            `json:"max_output_tokens,omitempty"`
+	UserId          string         `json:"user_id,omitempty"`
+}
+
+type baiduMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type baiduChatResponse struct {
+	Id               string `json:"id"`
+	Object           string `json:"object"`
+	Created          int64  `json:"created"`
+	SentenceId       *int   `json:"sentence_id,omitempty"`
+	IsEnd            *bool  `json:"is_end,omitempty"`
+	IsTruncated      bool   `json:"is_truncated"`
+	Result           string `json:"result"`
+	NeedClearHistory bool   `json:"need_clear_history"`
+	FinishReason     string `json:"finish_reason"`
+	Usage            usage  `json:"usage"`
+}
+
+type baiduErrorResp struct {
+	ErrorCode int    `json:"error_code"`
+	ErrorMsg  string `json:"error_msg"`
+}
@@ -0,0 +1,322 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"llm-mock-server/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	bedrockModelPathPrefix = "/model/"
+
+	bedrockActionConverse                 = "converse"
+	bedrockActionConverseStream           = "converse-stream"
+	bedrockActionInvoke                   = "invoke"
+	bedrockActionInvokeWithResponseStream = "invoke-with-response-stream"
+
+	bedrockStopReasonEndTurn = "end_turn"
+	bedrockMockLatencyMs     = 100
+
+	bedrockEventMessageStart      = "messageStart"
+	bedrockEventContentBlockDelta = "contentBlockDelta"
+	bedrockEventContentBlockStop  = "contentBlockStop"
+	bedrockEventMessageStop       = "messageStop"
+	bedrockEventMetadata          = "metadata"
+	bedrockEventChunk             = "chunk"
+
+	bedrockErrorValidation = "ValidationException"
+)
+
+// bedrockDomainRegex matches hosts like "bedrock-runtime.us-east-1.amazonaws.com".
+var bedrockDomainRegex = regexp.MustCompile(`^bedrock-runtime\.[a-z0-9-]+\.amazonaws\.com$`)
+
+type bedrockProvider struct {
+	accessKeyId     string
+	secretAccessKey string
+}
+
+func (p *bedrockProvider) ShouldHandleRequest(ctx *gin.Context) bool {
+	context, _ := getRequestContext(ctx)
+	if !bedrockDomainRegex.MatchString(context.Host) {
+		return false
+	}
+	_, action := parseBedrockModelPath(context.Path)
+	switch action {
+	case bedrockActionConverse, bedrockActionConverseStream, bedrockActionInvoke, bedrockActionInvokeWithResponseStream:
+		return true
+	}
+	return false
+}
+
+// parseBedrockModelPath splits a path like "/model/{modelId}/converse" into the model ID and the action.
+func parseBedrockModelPath(path string) (string, string) {
+	if !strings.HasPrefix(path, bedrockModelPathPrefix) {
+		return "", ""
+	}
+	modelPath := strings.TrimPrefix(path, bedrockModelPathPrefix)
+	index := strings.LastIndex(modelPath, "/")
+	if index < 0 {
+		return "", ""
+	}
+	return modelPath[:index], modelPath[index+1:]
+}
+
+func (p *bedrockProvider) HandleChatCompletions(ctx *gin.Context) {
+	body, err := ctx.GetRawData()
+	if err != nil {
+		p.sendErrorResponse(ctx, http.StatusBadRequest, bedrockErrorValidation, err.Error())
+		return
+	}
+
+	// Validate the SigV4 signature
+	if err := verifyAwsSigV4(ctx.Request, body, p.accessKeyId, p.secretAccessKey); err != nil {
+		sigV4Err := err.(*awsSigV4Error)
+		p.sendErrorResponse(ctx, http.StatusForbidden, sigV4Err.ErrorType, sigV4Err.Message)
+		return
+	}
+
+	context, _ := getRequestContext(ctx)
+	modelId, action := parseBedrockModelPath(context.Path)
+	switch action {
+	case bedrockActionConverse, bedrockActionConverseStream:
+		p.handleConverse(ctx, body, modelId, action == bedrockActionConverseStream)
+	case bedrockActionInvoke, bedrockActionInvokeWithResponseStream:
+		p.handleInvoke(ctx, body, modelId, action == bedrockActionInvokeWithResponseStream)
+	}
+}
+
+func (p *bedrockProvider) sendErrorResponse(ctx *gin.Context, statusCode int, errorType, message string) {
+	ctx.Header("x-amzn-ErrorType", errorType)
+	ctx.JSON(statusCode, gin.H{"message": message})
+}
+
+func (p *bedrockProvider) handleConverse(ctx *gin.Context, body []byte, modelId string, isStream bool) {
+	// Bind request body
+	var converseRequest bedrockConverseRequest
+	if err := json.Unmarshal(body, &converseRequest); err != nil {
+		p.sendErrorResponse(ctx, http.StatusBadRequest, bedrockErrorValidation, fmt.Sprintf("Malformed input request: %v", err))
+		return
+	}
+
+	// Validate request body
+	if err := utils.Validate.Struct(converseRequest); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		for _, fieldError := range validationErrors {
+			p.sendErrorResponse(ctx, http.StatusBadRequest, bedrockErrorValidation, fmt.Sprintf("Malformed input request: %v", fieldError.Error()))
+			return
+		}
+	}
+
+	messages := converseRequest.Messages
+	response := prompt2Response(messages[len(messages)-1].StringContent())
+
+	if isStream {
+		p.handleConverseStreamResponse(ctx, response)
+	} else {
+		ctx.JSON(http.StatusOK, bedrockConverseResponse{
+			Output: bedrockConverseOutput{
+				Message: bedrockMessage{
+					Role:    roleAssistant,
+					Content: []bedrockContentBlock{{Text: response}},
+				},
+			},
+			StopReason: bedrockStopReasonEndTurn,
+			Usage:      createBedrockUsage(),
+			Metrics:    bedrockMetrics{LatencyMs: bedrockMockLatencyMs},
+		})
+	}
+}
+
+func (p *bedrockProvider) handleConverseStreamResponse(ctx *gin.Context, response string) {
+	events := []bedrockStreamEvent{
+		{Type: bedrockEventMessageStart, Payload: bedrockConverseStreamEvent{Role: roleAssistant}},
+	}
+	for _, s := range response {
+		events = append(events, bedrockStreamEvent{Type: bedrockEventContentBlockDelta, Payload: bedrockConverseStreamEvent{
+			ContentBlockIndex: ptr(0),
+			Delta:             &bedrockContentBlock{Text: string(s)},
+		}})
+	}
+	events = append(events,
+		bedrockStreamEvent{Type: bedrockEventContentBlockStop, Payload: bedrockConverseStreamEvent{ContentBlockIndex: ptr(0)}},
+		bedrockStreamEvent{Type: bedrockEventMessageStop, Payload: bedrockConverseStreamEvent{StopReason: bedrockStopReasonEndTurn}},
+		bedrockStreamEvent{Type: bedrockEventMetadata, Payload: bedrockConverseStreamEvent{
+			Usage:   ptr(createBedrockUsage()),
+			Metrics: &bedrockMetrics{LatencyMs: bedrockMockLatencyMs},
+		}},
+	)
+	p.streamEvents(ctx, events)
+}
+
+// handleInvoke handles the InvokeModel APIs, whose request and response bodies are in the native format of the model.
+// Only the Anthropic Claude Messages format is supported.
+func (p *bedrockProvider) handleInvoke(ctx *gin.Context, body []byte, modelId string, isStream bool) {
+	var chatRequest claudeChatMessageRequest
+	if err := json.Unmarshal(body, &chatRequest); err != nil {
+		p.sendErrorResponse(ctx, http.StatusBadRequest, bedrockErrorValidation, fmt.Sprintf("Malformed input request: %v", err))
+		return
+	}
+	if len(chatRequest.Messages) == 0 {
+		p.sendErrorResponse(ctx, http.StatusBadRequest, bedrockErrorValidation,
+			"Malformed input request: #: required key [messages] not found, please reformat your input and try again.")
+		return
+	}
+
+	messages := chatRequest.Messages
+	response := prompt2Response(messages[len(messages)-1].StringContent())
+	claude := &claudeProvider{}
+	if !isStream {
+		ctx.JSON(http.StatusOK, claude.createMessageResponse(modelId, response))
+		return
+	}
+
+	message := claude.createMessageResponse(modelId, "")
+	message.Content = []claudeContent{}
+	message.StopReason = nil
+	claudeEvents := []claudeStreamResponse{
+		{Type: claudeEventMessageStart, Message: &message},
+		{Type: claudeEventContentBlockStart, Index: ptr(0), ContentBlock: &claudeContent{Type: claudeContentTypeText, Text: ptr("")}},
+	}
+	for _, s := range response {
+		claudeEvents = append(claudeEvents, claudeStreamResponse{
+			Type:  claudeEventContentBlockDelta,
+			Index: ptr(0),
+			Delta: &claudeDelta{Type: claudeTextDeltaType, Text: string(s)},
+		})
+	}
+	claudeEvents = append(claudeEvents,
+		claudeStreamResponse{Type: claudeEventContentBlockStop, Index: ptr(0)},
+		claudeStreamResponse{
+			Type:  claudeEventMessageDelta,
+			Delta: &claudeDelta{StopReason: ptr(claudeStopReasonEndTurn)},
+			Usage: &claudeUsage{OutputTokens: completionMockUsage.CompletionTokens},
+		},
+		claudeStreamResponse{Type: claudeEventMessageStop},
+	)
+
+	// Each native event is wrapped in a chunk event, whose bytes field is the base64-encoded JSON of the event
+	var events []bedrockStreamEvent
+	for _, claudeEvent := range claudeEvents {
+		jsonStr, _ := json.Marshal(claudeEvent)
+		events = append(events, bedrockStreamEvent{Type: bedrockEventChunk, Payload: bedrockChunk{Bytes: jsonStr}})
+	}
+	p.streamEvents(ctx, events)
+}
+
+func (p *bedrockProvider) streamEvents(ctx *gin.Context, events []bedrockStreamEvent) {
+	ctx.Writer.Header().Set("Content-Type", awsEventStreamContentType)
+	dataChan := make(chan []byte)
+	stopChan := make(chan bool, 1)
+
+	go func() {
+		for _, event := range events {
+			payload, _ := json.Marshal(event.Payload)
+			dataChan <- encodeAwsEvent(event.Type, payload)
+
+			// Simulate response delay
+			time.Sleep(200 * time.Millisecond)
+		}
+		stopChan <- true
+	}()
+
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case data := <-dataChan:
+			_, _ = w.Write(data)
+			return true
+		case <-stopChan:
+			return false
+		}
+	})
+}
+
+func createBedrockUsage() bedrockUsage {
+	return bedrockUsage{
+		InputTokens:  completionMockUsage.PromptTokens,
+		OutputTokens: completionMockUsage.CompletionTokens,
+		TotalTokens:  completionMockUsage.TotalTokens,
+	}
+}
+
+type bedrockConverseRequest struct {
+	Messages                     []bedrockMessage       `json:"messages" validate:"required,min=1"`
+	System                       []bedrockContentBlock  `json:"system,omitempty"`
+	InferenceConfig              bedrockInferenceConfig `json:"inferenceConfig,omitempty"`
+	ToolConfig                   map[string]any         `json:"toolConfig,omitempty"`
+	AdditionalModelRequestFields map[string]any         `json:"additionalModelRequestFields,omitempty"`
+}
+
+type bedrockInferenceConfig struct {
+	MaxTokens     int      `json:"maxTokens,omitempty"`
+	Temperature   float64  `json:"temperature,omitempty"`
+	TopP          float64  `json:"topP,omitempty"`
+	StopSequences []string `json:"stopSequences,omitempty"`
+}
+
+type bedrockMessage struct {
+	Role    string                `json:"role"`
+	Content []bedrockContentBlock `json:"content"`
+}
+
+// StringContent returns the concatenated text of all text blocks.
+func (m *bedrockMessage) StringContent() string {
+	var content string
+	for _, block := range m.Content {
+		content += block.Text
+	}
+	return content
+}
+
+type bedrockContentBlock struct {
+	Text string `json:"text,omitempty"`
+}
+
+type bedrockConverseResponse struct {
+	Output     bedrockConverseOutput `json:"output"`
+	StopReason string                `json:"stopReason"`
+	Usage      bedrockUsage          `json:"usage"`
+	Metrics    bedrockMetrics        `json:"metrics"`
+}
+
+type bedrockConverseOutput struct {
+	Message bedrockMessage `json:"message"`
+}
+
+type bedrockUsage struct {
+	InputTokens  int `json:"inputTokens"`
+	OutputTokens int `json:"outputTokens"`
+	TotalTokens  int `json:"totalTokens"`
+}
+
+type bedrockMetrics struct {
+	LatencyMs int `json:"latencyMs"`
+}
+
+type bedrockStreamEvent struct {
+	Type    string
+	Payload any
+}
+
+// bedrockConverseStreamEvent is the payload of a ConverseStream event.
+// Only the fields relevant to the event type are populated.
+type bedrockConverseStreamEvent struct {
+	Role              string               `json:"role,omitempty"`
+	ContentBlockIndex *int                 `json:"contentBlockIndex,omitempty"`
+	Delta             *bedrockContentBlock `json:"delta,omitempty"`
+	StopReason        string               `json:"stopReason,omitempty"`
+	Usage             *bedrockUsage        `json:"usage,omitempty"`
+	Metrics           *bedrockMetrics      `json:"metrics,omitempty"`
+}
+
+type bedrockChunk struct {
+	Bytes []byte `json:"bytes"`
+}
@@ -0,0 +1,358 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"llm-mock-server/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	claudeDomain          = "api.anthropic.com"
+	claudeChatMessagePath = "/v1/messages"
+	claudeMessageMockId   = "msg_llm_mock"
+
+	claudeObjectMessage     = "message"
+	claudeObjectError       = "error"
+	claudeContentTypeText   = "text"
+	claudeTextDeltaType     = "text_delta"
+	claudeStopReasonEndTurn = "end_turn"
+
+	claudeContentTypeThinking = "thinking"
+	claudeThinkingDeltaType   = "thinking_delta"
+	claudeSignatureDeltaType  = "signature_delta"
+	claudeThinkingTypeEnabled = "enabled"
+	// claudeMinThinkingBudget is the minimum budget_tokens of extended thinking.
+	claudeMinThinkingBudget = 1024
+
+	claudeEventMessageStart      = "message_start"
+	claudeEventContentBlockStart = "content_block_start"
+	claudeEventPing              = "ping"
+	claudeEventContentBlockDelta = "content_block_delta"
+	claudeEventContentBlockStop  = "content_block_stop"
+	claudeEventMessageDelta      = "message_delta"
+	claudeEventMessageStop       = "message_stop"
+
+	claudeErrorTypeInvalidRequest = "invalid_request_error"
+	claudeErrorTypeAuthentication = "authentication_error"
+)
+
+type claudeProvider struct{}
+
+func (p *claudeProvider) ShouldHandleRequest(ctx *gin.Context) bool {
+	context, _ := getRequestContext(ctx)
+	return context.Host == claudeDomain && context.Path == claudeChatMessagePath
+}
+
+func (p *claudeProvider) HandleChatCompletions(ctx *gin.Context) {
+	// Validate x-api-key header
+	if ctx.GetHeader("x-api-key") == "" {
+		p.sendErrorResponse(ctx, http.StatusUnauthorized,
+			claudeErrorTypeAuthentication, "x-api-key header is required")
+		return
+	}
+
+	// Validate anthropic-version header
+	if ctx.GetHeader("anthropic-version") == "" {
+		p.sendErrorResponse(ctx, http.StatusBadRequest,
+			claudeErrorTypeInvalidRequest, "anthropic-version: header is required")
+		return
+	}
+
+	// Bind request body
+	var chatRequest claudeChatMessageRequest
+	if err := ctx.ShouldBindJSON(&chatRequest); err != nil {
+		p.sendErrorResponse(ctx, http.StatusBadRequest,
+			claudeErrorTypeInvalidRequest, err.Error())
+		return
+	}
+
+	p.handleMessages(ctx, chatRequest)
+}
+
+// handleMessages handles the authenticated Messages request, which may also be sent by other platforms hosting Claude.
+func (p *claudeProvider) handleMessages(ctx *gin.Context, chatRequest claudeChatMessageRequest) {
+	// Validate request body
+	if err := utils.Validate.Struct(chatRequest); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		for _, fieldError := range validationErrors {
+			p.sendErrorResponse(ctx, http.StatusBadRequest,
+				claudeErrorTypeInvalidRequest, fieldError.Error())
+			return
+		}
+	}
+
+	// Validate extended thinking budget
+	thinking := chatRequest.Thinking
+	if thinking != nil && thinking.Type == claudeThinkingTypeEnabled {
+		if thinking.BudgetTokens < claudeMinThinkingBudget {
+			p.sendErrorResponse(ctx, http.StatusBadRequest, claudeErrorTypeInvalidRequest,
+				fmt.Sprintf("thinking.enabled.budget_tokens: Input should be greater than or equal to %d", claudeMinThinkingBudget))
+			return
+		}
+		if thinking.BudgetTokens >= chatRequest.MaxTokens {
+			p.sendErrorResponse(ctx, http.StatusBadRequest, claudeErrorTypeInvalidRequest,
+				"`max_tokens` must be greater than `thinking.budget_tokens`")
+			return
+		}
+	}
+
+	messages := chatRequest.Messages
+	prompt := messages[len(messages)-1].StringContent()
+	response := prompt2Response(prompt)
+
+	var reasoning *mockReasoning
+	if thinking != nil && thinking.Type == claudeThinkingTypeEnabled {
+		reasoning = newMockReasoning(response, thinking.BudgetTokens)
+	}
+
+	if chatRequest.Stream {
+		p.handleStreamResponse(ctx, chatRequest, response, reasoning)
+	} else {
+		p.handleNonStreamResponse(ctx, chatRequest, response, reasoning)
+	}
+}
+
+func (p *claudeProvider) sendErrorResponse(ctx *gin.Context, statusCode int, errorType, errorMsg string) {
+	ctx.JSON(statusCode, claudeErrorResponse{
+		Type: claudeObjectError,
+		Error: claudeError{
+			Type:    errorType,
+			Message: errorMsg,
+		},
+	})
+}
+
+func (p *claudeProvider) handleStreamResponse(ctx *gin.Context, chatRequest claudeChatMessageRequest, response string, reasoning *mockReasoning) {
+	utils.SetEventStreamHeaders(ctx)
+	dataChan := make(chan streamEvent)
+	stopChan := make(chan bool, 1)
+
+	go func() {
+		message := p.createMessageResponse(chatRequest.Model, "")
+		message.Content = []claudeContent{}
+		message.StopReason = nil
+		dataChan <- p.createStreamEvent(claudeEventMessageStart, claudeStreamResponse{Message: &message})
+
+		// The thinking block comes before the text block
+		textIndex := 0
+		outputTokens := completionMockUsage.CompletionTokens
+		if reasoning != nil {
+			dataChan <- p.createStreamEvent(claudeEventContentBlockStart, claudeStreamResponse{
+				Index:        ptr(0),
+				ContentBlock: &claudeContent{Type: claudeContentTypeThinking, Thinking: ptr(""), Signature: ptr("")},
+			})
+			dataChan <- p.createStreamEvent(claudeEventPing, claudeStreamResponse{})
+			for _, chunk := range reasoning.Chunks() {
+				dataChan <- p.createStreamEvent(claudeEventContentBlockDelta, claudeStreamResponse{
+					Index: ptr(0),
+					Delta: &claudeDelta{Type: claudeThinkingDeltaType, Thinking: chunk},
+				})
+				time.Sleep(reasoningMockDelay)
+			}
+			dataChan <- p.createStreamEvent(claudeEventContentBlockDelta, claudeStreamResponse{
+				Index: ptr(0),
+				Delta: &claudeDelta{Type: claudeSignatureDeltaType, Signature: reasoning.Signature()},
+			})
+			dataChan <- p.createStreamEvent(claudeEventContentBlockStop, claudeStreamResponse{Index: ptr(0)})
+			textIndex = 1
+			outputTokens += reasoning.Tokens
+		}
+
+		dataChan <- p.createStreamEvent(claudeEventContentBlockStart, claudeStreamResponse{
+			Index:        ptr(textIndex),
+			ContentBlock: &claudeContent{Type: claudeContentTypeText, Text: ptr("")},
+		})
+		if reasoning == nil {
+			dataChan <- p.createStreamEvent(claudeEventPing, claudeStreamResponse{})
+		}
+
+		for _, s := range response {
+			dataChan <- p.createStreamEvent(claudeEventContentBlockDelta, claudeStreamResponse{
+				Index: ptr(textIndex),
+				Delta: &claudeDelta{Type: claudeTextDeltaType, Text: string(s)},
+			})
+
+			// Simulate response delay
+			time.Sleep(200 * time.Millisecond)
+		}
+
+		dataChan <- p.createStreamEvent(claudeEventContentBlockStop, claudeStreamResponse{Index: ptr(textIndex)})
+		dataChan <- p.createStreamEvent(claudeEventMessageDelta, claudeStreamResponse{
+			Delta: &claudeDelta{StopReason: ptr(claudeStopReasonEndTurn)},
+			Usage: &claudeUsage{OutputTokens: outputTokens},
+		})
+		stopChan <- true
+	}()
+
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case event := <-dataChan:
+			ctx.Render(-1, event)
+			return true
+		case <-stopChan:
+			ctx.Render(-1, p.createStreamEvent(claudeEventMessageStop, claudeStreamResponse{}))
+			return false
+		}
+	})
+}
+
+func (p *claudeProvider) createStreamEvent(event string, response claudeStreamResponse) streamEvent {
+	response.Type = event
+	jsonStr, _ := json.Marshal(response)
+	return streamEvent{Event: event, Data: fmt.Sprintf("data: %s", jsonStr)}
+}
+
+func (p *claudeProvider) handleNonStreamResponse(ctx *gin.Context, chatRequest claudeChatMessageRequest, response string, reasoning *mockReasoning) {
+	message := p.createMessageResponse(chatRequest.Model, response)
+	if reasoning != nil {
+		thinkingBlock := claudeContent{
+			Type:      claudeContentTypeThinking,
+			Thinking:  ptr(reasoning.Text),
+			Signature: ptr(reasoning.Signature()),
+		}
+		message.Content = append([]claudeContent{thinkingBlock}, message.Content...)
+		message.Usage.OutputTokens += reasoning.Tokens
+	}
+	ctx.JSON(http.StatusOK, message)
+}
+
+func (p *claudeProvider) createMessageResponse(model, response string) claudeChatMessageResponse {
+	return claudeChatMessageResponse{
+		Id:    claudeMessageMockId,
+		Type:  claudeObjectMessage,
+		Role:  roleAssistant,
+		Model: model,
+		Content: []claudeContent{
+			{
+				Type: claudeContentTypeText,
+				Text: ptr(response),
+			},
+		},
+		StopReason: ptr(claudeStopReasonEndTurn),
+		Usage: claudeUsage{
+			InputTokens:  completionMockUsage.PromptTokens,
+			OutputTokens: completionMockUsage.CompletionTokens,
+		},
+	}
+}
+
+type claudeChatMessageRequest struct {
+	Model         string          `json:"model" validate:"required"`
+	Messages      []claudeMessage `json:"messages" validate:"required,min=1"`
+	System        any             `json:"system,omitempty"`
+	MaxTokens     int             `json:"max_tokens" validate:"required"`
+	StopSequences []string        `json:"stop_sequences,omitempty"`
+	Stream        bool            `json:"stream,omitempty"`
+	Temperature   float64         `json:"temperature,omitempty"`
+	TopP          float64         `json:"top_p,omitempty"`
+	TopK          int             `json:"top_k,omitempty"`
+	Tools         []claudeTool    `json:"tools,omitempty"`
+	ToolChoice    map[string]any  `json:"tool_choice,omitempty"`
+	Metadata      map[string]any  `json:"metadata,omitempty"`
+	Thinking      *claudeThinking `json:"thinking,omitempty"`
+	// AnthropicVersion is given in the body instead of the header on platforms hosting Claude.
+	AnthropicVersion string `json:"anthropic_version,omitempty"`
+}
+
+// claudeThinking is the configuration of extended thinking.
+type claudeThinking struct {
+	Type         string `json:"type"`
+	BudgetTokens int    `json:"budget_tokens,omitempty"`
+}
+
+type claudeMessage struct {
+	Role    string `json:"role"`
+	Content any    `json:"content"`
+}
+
+// StringContent returns the text of the message, concatenating all text blocks
+// when the content is given as a list of content blocks.
+func (m *claudeMessage) StringContent() string {
+	content, ok := m.Content.(string)
+	if ok {
+		return content
+	}
+	contentList, ok := m.Content.([]any)
+	if ok {
+		var contentStr string
+		for _, contentItem := range contentList {
+			contentMap, ok := contentItem.(map[string]any)
+			if !ok {
+				continue
+			}
+			if contentMap["type"] == claudeContentTypeText {
+				if subStr, ok := contentMap[claudeContentTypeText].(string); ok {
+					contentStr += subStr
+				}
+			}
+		}
+		return contentStr
+	}
+	return ""
+}
+
+type claudeTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	InputSchema map[string]any `json:"input_schema,omitempty"`
+}
+
+type claudeChatMessageResponse struct {
+	Id           string          `json:"id"`
+	Type         string          `json:"type"`
+	Role         string          `json:"role"`
+	Content      []claudeContent `json:"content"`
+	Model        string          `json:"model"`
+	StopReason   *string         `json:"stop_reason"`
+	StopSequence *string         `json:"stop_sequence"`
+	Usage        claudeUsage     `json:"usage"`
+}
+
+type claudeContent struct {
+	Type      string  `json:"type"`
+	Text      *string `json:"text,omitempty"`
+	Thinking  *string `json:"thinking,omitempty"`
+	Signature *string `json:"signature,omitempty"`
+}
+
+type claudeUsage struct {
+	InputTokens  int `json:"input_tokens,omitempty"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// claudeStreamResponse is the payload of every event in the Messages streaming protocol.
+// Only the fields relevant to the event type are populated.
+type claudeStreamResponse struct {
+	Type         string                     `json:"type"`
+	Message      *claudeChatMessageResponse `json:"message,omitempty"`
+	Index        *int                       `json:"index,omitempty"`
+	ContentBlock *claudeContent             `json:"content_block,omitempty"`
+	Delta        *claudeDelta               `json:"delta,omitempty"`
+	Usage        *claudeUsage               `json:"usage,omitempty"`
+}
+
+type claudeDelta struct {
+	Type         string  `json:"type,omitempty"`
+	Text         string  `json:"text,omitempty"`
+	Thinking     string  `json:"thinking,omitempty"`
+	Signature    string  `json:"signature,omitempty"`
+	StopReason   *string `json:"stop_reason,omitempty"`
+	StopSequence *string `json:"stop_sequence,omitempty"`
+}
+
+type claudeErrorResponse struct {
+	Type  string      `json:"type"`
+	Error claudeError `json:"error"`
+}
+
+type claudeError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,282 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"llm-mock-server/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	cloudflareDomain = "api.cloudflare.com"
+	// cloudflareAccountsPathPrefix is the prefix of the Workers AI paths, which is followed by the account ID.
+	cloudflareAccountsPathPrefix = "/client/v4/accounts/"
+	// cloudflareRunPathInfix is followed by the model name like "@cf/meta/llama-3.1-8b-instruct".
+	cloudflareRunPathInfix               = "/ai/run/"
+	cloudflareChatCompletionsPathSuffix  = "/ai/v1/chat/completions"
+	cloudflareModelPrefixWorkersAi       = "@cf/"
+	cloudflareModelPrefixHuggingFace     = "@hf/"
+	cloudflareGatewayDomain              = "gateway.ai.cloudflare.com"
+	cloudflareGatewayPathPrefix          = "/v1/"
+	cloudflareGatewayRoute               = "/v1/:accountId/:gatewayId/*path"
+	cloudflareGatewayAuthorizationHeader = "cf-aig-authorization"
+
+	cloudflareProviderWorkersAi      = "workers-ai"
+	cloudflareProviderOpenAi         = "openai"
+	cloudflareProviderCompat         = "compat"
+	cloudflareProviderAnthropic      = "anthropic"
+	cloudflareProviderGoogleAiStudio = "google-ai-studio"
+	cloudflareProviderCohere         = "cohere"
+	cloudflareProviderGroq           = "groq"
+	cloudflareProviderDeepSeek       = "deepseek"
+	cloudflareProviderMistral        = "mistral"
+	cloudflareProviderAzureOpenAi    = "azure-openai"
+
+	cloudflareErrorCodeAuthentication      = 10000
+	cloudflareErrorCodeInvalidObject       = 7003
+	cloudflareErrorCodeInvalidInput        = 5006
+	cloudflareErrorCodeNoSuchModel         = 5007
+	cloudflareErrorCodeGatewayNotFound     = 2001
+	cloudflareErrorCodeInvalidProvider     = 2008
+	cloudflareErrorCodeGatewayUnauthorized = 2009
+)
+
+type cloudflareProvider struct {
+	accountId string
+	apiToken  string
+	openAi    *openAiProvider
+}
+
+func (p *cloudflareProvider) ShouldHandleRequest(ctx *gin.Context) bool {
+	context, _ := getRequestContext(ctx)
+	return context.Host == cloudflareGatewayDomain ||
+		(context.Host == cloudflareDomain && strings.HasPrefix(context.Path, cloudflareAccountsPathPrefix))
+}
+
+// registerRoutes registers the AI Gateway route in the form of "/v1/{account_id}/{gateway_id}/{provider}/{path}",
+// which is only served to the gateway host as it would match the paths of many other APIs.
+func (p *cloudflareProvider) registerRoutes(server *gin.Engine) {
+	server.POST(cloudflareGatewayRoute, handleProviderChatCompletions(p))
+}
+
+func (p *cloudflareProvider) HandleChatCompletions(ctx *gin.Context) {
+	context, _ := getRequestContext(ctx)
+	if context.Host == cloudflareGatewayDomain {
+		p.handleGateway(ctx, context.Path)
+		return
+	}
+
+	// Validate API token
+	if ctx.GetHeader("Authorization") != "Bearer "+p.apiToken {
+		p.sendErrorResponse(ctx, http.StatusUnauthorized, cloudflareErrorCodeAuthentication, "Authentication error")
+		return
+	}
+
+	// Validate account ID
+	accountId, rest, _ := strings.Cut(strings.TrimPrefix(context.Path, cloudflareAccountsPathPrefix), "/")
+	if accountId != p.accountId {
+		p.sendErrorResponse(ctx, http.StatusNotFound, cloudflareErrorCodeInvalidObject,
+			fmt.Sprintf("Could not route to %s, perhaps your object identifier is invalid?", context.Path))
+		return
+	}
+
+	rest = "/" + rest
+	switch {
+	case rest == cloudflareChatCompletionsPathSuffix:
+		// The response format is compatible with OpenAI's
+		p.openAi.HandleChatCompletions(ctx)
+	case strings.HasPrefix(rest, cloudflareRunPathInfix):
+		p.handleRun(ctx, strings.TrimPrefix(rest, cloudflareRunPathInfix))
+	default:
+		p.sendErrorResponse(ctx, http.StatusNotFound, cloudflareErrorCodeInvalidObject,
+			fmt.Sprintf("Could not route to %s, perhaps your object identifier is invalid?", context.Path))
+	}
+}
+
+func (p *cloudflareProvider) sendErrorResponse(ctx *gin.Context, statusCode int, errorCode int, errorMsg string) {
+	ctx.JSON(statusCode, cloudflareResponse{
+		Success:  false,
+		Errors:   []cloudflareMessage{{Code: errorCode, Message: errorMsg}},
+		Messages: []cloudflareMessage{},
+	})
+}
+
+func (p *cloudflareProvider) handleRun(ctx *gin.Context, model string) {
+	if !strings.HasPrefix(model, cloudflareModelPrefixWorkersAi) && !strings.HasPrefix(model, cloudflareModelPrefixHuggingFace) {
+		p.sendErrorResponse(ctx, http.StatusBadRequest, cloudflareErrorCodeNoSuchModel,
+			fmt.Sprintf("No such model %s or task", model))
+		return
+	}
+
+	// Bind request body
+	var runRequest cloudflareRunRequest
+	if err := ctx.ShouldBindJSON(&runRequest); err != nil {
+		p.sendErrorResponse(ctx, http.StatusBadRequest, cloudflareErrorCodeInvalidInput, err.Error())
+		return
+	}
+
+	// Validate request body
+	if err := utils.Validate.Struct(runRequest); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		for _, fieldError := range validationErrors {
+			p.sendErrorResponse(ctx, http.StatusBadRequest, cloudflareErrorCodeInvalidInput, fieldError.Error())
+			return
+		}
+	}
+
+	// Either a prompt or a list of messages is required
+	prompt := runRequest.Prompt
+	if len(runRequest.Messages) > 0 {
+		lastMessage := runRequest.Messages[len(runRequest.Messages)-1]
+		prompt = ""
+		if lastMessage.IsStringContent() {
+			prompt = lastMessage.StringContent()
+		}
+	} else if prompt == "" {
+		p.sendErrorResponse(ctx, http.StatusBadRequest, cloudflareErrorCodeInvalidInput,
+			"Error: oneOf at '/' not met, 0 matches: required properties at '/' are 'prompt'; required properties at '/' are 'messages'")
+		return
+	}
+	response := prompt2Response(prompt)
+
+	if runRequest.Stream {
+		p.handleRunStreamResponse(ctx, response)
+	} else {
+		ctx.JSON(http.StatusOK, cloudflareResponse{
+			Result: cloudflareRunResult{
+				Response: response,
+				Usage:    &completionMockUsage,
+			},
+			Success:  true,
+			Errors:   []cloudflareMessage{},
+			Messages: []cloudflareMessage{},
+		})
+	}
+}
+
+// handleRunStreamResponse streams the bare results without the envelope, with the usage in the last chunk.
+func (p *cloudflareProvider) handleRunStreamResponse(ctx *gin.Context, response string) {
+	utils.SetEventStreamHeaders(ctx)
+	dataChan := make(chan string)
+	stopChan := make(chan bool, 1)
+
+	go func() {
+		for _, s := range response {
+			jsonStr, _ := json.Marshal(cloudflareRunResult{Response: string(s)})
+			dataChan <- string(jsonStr)
+
+			// Simulate response delay
+			time.Sleep(200 * time.Millisecond)
+		}
+		jsonStr, _ := json.Marshal(cloudflareRunResult{Usage: &completionMockUsage})
+		dataChan <- string(jsonStr)
+		stopChan <- true
+	}()
+
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case data := <-dataChan:
+			ctx.Render(-1, streamEvent{Data: "data: " + data})
+			return true
+		case <-stopChan:
+			ctx.Render(-1, streamEvent{Data: "data: [DONE]"})
+			return false
+		}
+	})
+}
+
+// handleGateway handles the AI Gateway requests in the form of "/v1/{account_id}/{gateway_id}/{provider}/{path}"
+// by forwarding them to the mocked upstream provider.
+func (p *cloudflareProvider) handleGateway(ctx *gin.Context, path string) {
+	segments := strings.SplitN(strings.TrimPrefix(path, cloudflareGatewayPathPrefix), "/", 4)
+	if len(segments) < 4 || segments[0] != p.accountId {
+		p.sendErrorResponse(ctx, http.StatusBadRequest, cloudflareErrorCodeGatewayNotFound,
+			"Please configure AI Gateway in the Cloudflare dashboard")
+		return
+	}
+	accountId, providerName, upstreamPath := segments[0], segments[2], "/"+segments[3]
+
+	// The gateway itself is authenticated only if the header is given, while the upstream provider authenticates
+	// the forwarded request as usual
+	if auth := ctx.GetHeader(cloudflareGatewayAuthorizationHeader); auth != "" && auth != "Bearer "+p.apiToken {
+		p.sendErrorResponse(ctx, http.StatusUnauthorized, cloudflareErrorCodeGatewayUnauthorized, "Unauthorized")
+		return
+	}
+
+	host, upstreamPath, ok := cloudflareGatewayUpstream(accountId, providerName, upstreamPath)
+	if !ok {
+		p.sendErrorResponse(ctx, http.StatusBadRequest, cloudflareErrorCodeInvalidProvider, "Invalid provider")
+		return
+	}
+	ctx.Request.Host = host
+	ctx.Request.URL.Path = upstreamPath
+	handleChatCompletions(ctx)
+}
+
+// cloudflareGatewayUpstream returns the host and path of the upstream request forwarded by AI Gateway.
+func cloudflareGatewayUpstream(accountId, providerName, path string) (string, string, bool) {
+	switch providerName {
+	case cloudflareProviderWorkersAi:
+		if strings.HasPrefix(path, "/v1/") {
+			return cloudflareDomain, cloudflareAccountsPathPrefix + accountId + "/ai" + path, true
+		}
+		return cloudflareDomain, cloudflareAccountsPathPrefix + accountId + "/ai/run" + path, true
+	case cloudflareProviderOpenAi, cloudflareProviderCompat:
+		return "api.openai.com", "/v1" + path, true
+	case cloudflareProviderAnthropic:
+		return claudeDomain, path, true
+	case cloudflareProviderGoogleAiStudio:
+		return geminiDomain, path, true
+	case cloudflareProviderCohere:
+		return cohereDomain, path, true
+	case cloudflareProviderGroq:
+		return "api.groq.com", "/openai/v1" + path, true
+	case cloudflareProviderDeepSeek:
+		return "api.deepseek.com", path, true
+	case cloudflareProviderMistral:
+		return "api.mistral.ai", "/v1" + path, true
+	case cloudflareProviderAzureOpenAi:
+		// The path is in the form of "/{resource_name}/{deployment_name}/{path}"
+		segments := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 3)
+		if len(segments) < 3 {
+			return "", "", false
+		}
+		return segments[0] + ".openai.azure.com", "/openai/deployments/" + segments[1] + "/" + segments[2], true
+	}
+	return "", "", false
+}
+
+// cloudflareResponse is the envelope of all Cloudflare API responses.
+type cloudflareResponse struct {
+	Result   any                 `json:"result"`
+	Success  bool                `json:"success"`
+	Errors   []cloudflareMessage `json:"errors"`
+	Messages []cloudflareMessage `json:"messages"`
+}
+
+type cloudflareMessage struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type cloudflareRunRequest struct {
+	Prompt      string        `json:"prompt,omitempty"`
+	Messages    []chatMessage `json:"messages,omitempty"`
+	Stream      bool          `json:"stream,omitempty"`
+	MaxTokens   int           `json:"max_tokens,omitempty" validate:"omitempty,gte=1"`
+	Temperature float64       `json:"temperature,omitempty" validate:"omitempty,gte=0,lte=5"`
+	TopP        float64       `json:"top_p,omitempty" validate:"omitempty,gte=0,lte=2"`
+	Raw         bool          `json:"raw,omitempty"`
+}
+
+type cloudflareRunResult struct {
+	Response string `json:"response"`
+	Usage    *usage `json:"usage,omitempty"`
+}
@@ -0,0 +1,341 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"llm-mock-server/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	cohereDomain         = "api.cohere.com"
+	cohereChatPath       = "/v2/chat"
+	cohereMockId         = "cohere-llm-mock"
+	cohereToolCallMockId = "cohere-tool-call-llm-mock"
+
+	cohereRoleTool = "tool"
+
+	cohereContentTypeText      = "text"
+	cohereToolCallTypeFunction = "function"
+	cohereFinishReasonComplete = "COMPLETE"
+	cohereFinishReasonToolCall = "TOOL_CALL"
+
+	cohereEventMessageStart  = "message-start"
+	cohereEventContentStart  = "content-start"
+	cohereEventContentDelta  = "content-delta"
+	cohereEventContentEnd    = "content-end"
+	cohereEventToolPlanDelta = "tool-plan-delta"
+	cohereEventToolCallStart = "tool-call-start"
+	cohereEventToolCallDelta = "tool-call-delta"
+	cohereEventToolCallEnd   = "tool-call-end"
+	cohereEventMessageEnd    = "message-end"
+)
+
+type cohereProvider struct{}
+
+func (p *cohereProvider) ShouldHandleRequest(ctx *gin.Context) bool {
+	context, _ := getRequestContext(ctx)
+	return context.Host == cohereDomain && context.Path == cohereChatPath
+}
+
+func (p *cohereProvider) HandleChatCompletions(ctx *gin.Context) {
+	// Validate Authorization header
+	if ctx.GetHeader("Authorization") == "" {
+		p.sendErrorResponse(ctx, http.StatusUnauthorized, "no api key supplied")
+		return
+	}
+
+	// Bind request body
+	var chatRequest cohereChatRequest
+	if err := ctx.ShouldBindJSON(&chatRequest); err != nil {
+		p.sendErrorResponse(ctx, http.StatusBadRequest, fmt.Sprintf("invalid request: %v", err.Error()))
+		return
+	}
+
+	// Validate request body
+	if err := utils.Validate.Struct(chatRequest); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		for _, fieldError := range validationErrors {
+			p.sendErrorResponse(ctx, http.StatusBadRequest, fmt.Sprintf("invalid request: %v", fieldError.Error()))
+			return
+		}
+	}
+
+	lastMessage := chatRequest.Messages[len(chatRequest.Messages)-1]
+	response := prompt2Response(lastMessage.StringContent())
+
+	// Call the first tool if any tool is provided, unless the tool result is already given
+	var toolCall *cohereToolCall
+	if len(chatRequest.Tools) > 0 && lastMessage.Role != cohereRoleTool {
+		arguments, _ := json.Marshal(map[string]string{"query": response})
+		toolCall = &cohereToolCall{
+			Id:   cohereToolCallMockId,
+			Type: cohereToolCallTypeFunction,
+			Function: &cohereFunctionCall{
+				Name:      chatRequest.Tools[0].Function.Name,
+				Arguments: string(arguments),
+			},
+		}
+	}
+
+	if chatRequest.Stream {
+		p.handleStreamResponse(ctx, response, toolCall)
+	} else {
+		p.handleNonStreamResponse(ctx, response, toolCall)
+	}
+}
+
+func (p *cohereProvider) sendErrorResponse(ctx *gin.Context, statusCode int, message string) {
+	ctx.JSON(statusCode, cohereErrorResponse{
+		Id:      cohereMockId,
+		Message: message,
+	})
+}
+
+func (p *cohereProvider) handleStreamResponse(ctx *gin.Context, response string, toolCall *cohereToolCall) {
+	utils.SetEventStreamHeaders(ctx)
+	dataChan := make(chan streamEvent)
+	stopChan := make(chan bool, 1)
+
+	go func() {
+		dataChan <- p.createStreamEvent(cohereEventMessageStart, cohereStreamResponse{
+			Id: cohereMockId,
+			Delta: &cohereStreamDelta{Message: &cohereStreamMessage{
+				Role: roleAssistant,
+			}},
+		})
+
+		finishReason := cohereFinishReasonComplete
+		if toolCall != nil {
+			finishReason = cohereFinishReasonToolCall
+			for _, s := range p.toolPlan(toolCall) {
+				dataChan <- p.createStreamEvent(cohereEventToolPlanDelta, cohereStreamResponse{
+					Delta: &cohereStreamDelta{Message: &cohereStreamMessage{ToolPlan: string(s)}},
+				})
+			}
+			dataChan <- p.createStreamEvent(cohereEventToolCallStart, cohereStreamResponse{
+				Index: ptr(0),
+				Delta: &cohereStreamDelta{Message: &cohereStreamMessage{ToolCalls: &cohereToolCall{
+					Id:       toolCall.Id,
+					Type:     toolCall.Type,
+					Function: &cohereFunctionCall{Name: toolCall.Function.Name},
+				}}},
+			})
+			for _, s := range toolCall.Function.Arguments {
+				dataChan <- p.createStreamEvent(cohereEventToolCallDelta, cohereStreamResponse{
+					Index: ptr(0),
+					Delta: &cohereStreamDelta{Message: &cohereStreamMessage{ToolCalls: &cohereToolCall{
+						Function: &cohereFunctionCall{Arguments: string(s)},
+					}}},
+				})
+
+				// Simulate response delay
+				time.Sleep(200 * time.Millisecond)
+			}
+			dataChan <- p.createStreamEvent(cohereEventToolCallEnd, cohereStreamResponse{Index: ptr(0)})
+		} else {
+			dataChan <- p.createStreamEvent(cohereEventContentStart, cohereStreamResponse{
+				Index: ptr(0),
+				Delta: &cohereStreamDelta{Message: &cohereStreamMessage{
+					Content: &cohereContent{Type: cohereContentTypeText, Text: ptr("")},
+				}},
+			})
+			for _, s := range response {
+				dataChan <- p.createStreamEvent(cohereEventContentDelta, cohereStreamResponse{
+					Index: ptr(0),
+					Delta: &cohereStreamDelta{Message: &cohereStreamMessage{
+						Content: &cohereContent{Text: ptr(string(s))},
+					}},
+				})
+
+				// Simulate response delay
+				time.Sleep(200 * time.Millisecond)
+			}
+			dataChan <- p.createStreamEvent(cohereEventContentEnd, cohereStreamResponse{Index: ptr(0)})
+		}
+
+		dataChan <- p.createStreamEvent(cohereEventMessageEnd, cohereStreamResponse{
+			Delta: &cohereStreamDelta{
+				FinishReason: finishReason,
+				Usage:        ptr(createCohereUsage()),
+			},
+		})
+		stopChan <- true
+	}()
+
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case event := <-dataChan:
+			ctx.Render(-1, event)
+			return true
+		case <-stopChan:
+			return false
+		}
+	})
+}
+
+func (p *cohereProvider) createStreamEvent(event string, response cohereStreamResponse) streamEvent {
+	response.Type = event
+	jsonStr, _ := json.Marshal(response)
+	return streamEvent{Event: event, Data: fmt.Sprintf("data: %s", jsonStr)}
+}
+
+func (p *cohereProvider) handleNonStreamResponse(ctx *gin.Context, response string, toolCall *cohereToolCall) {
+	chatResponse := cohereChatResponse{
+		Id:           cohereMockId,
+		FinishReason: cohereFinishReasonComplete,
+		Message: cohereResponseMessage{
+			Role: roleAssistant,
+		},
+		Usage: createCohereUsage(),
+	}
+	if toolCall != nil {
+		chatResponse.FinishReason = cohereFinishReasonToolCall
+		chatResponse.Message.ToolPlan = p.toolPlan(toolCall)
+		chatResponse.Message.ToolCalls = []cohereToolCall{*toolCall}
+	} else {
+		chatResponse.Message.Content = []cohereContent{{Type: cohereContentTypeText, Text: ptr(response)}}
+	}
+	ctx.JSON(http.StatusOK, chatResponse)
+}
+
+func (p *cohereProvider) toolPlan(toolCall *cohereToolCall) string {
+	return fmt.Sprintf("I will use the %s tool.", toolCall.Function.Name)
+}
+
+func createCohereUsage() cohereUsage {
+	return cohereUsage{
+		BilledUnits: cohereBilledUnits{
+			InputTokens:  completionMockUsage.PromptTokens,
+			OutputTokens: completionMockUsage.CompletionTokens,
+		},
+		Tokens: cohereTokens{
+			InputTokens:  completionMockUsage.PromptTokens,
+			OutputTokens: completionMockUsage.CompletionTokens,
+		},
+	}
+}
+
+type cohereChatRequest struct {
+	Model            string          `json:"model" validate:"required"`
+	Messages         []cohereMessage `json:"messages" validate:"required,min=1"`
+	Tools            []tool          `json:"tools,omitempty"`
+	Stream           bool            `json:"stream,omitempty"`
+	MaxTokens        int             `json:"max_tokens,omitempty"`
+	StopSequences    []string        `json:"stop_sequences,omitempty"`
+	Temperature      float64         `json:"temperature,omitempty"`
+	Seed             int             `json:"seed,omitempty"`
+	FrequencyPenalty float64         `json:"frequency_penalty,omitempty"`
+	PresencePenalty  float64         `json:"presence_penalty,omitempty"`
+	K                int             `json:"k,omitempty"`
+	P                float64         `json:"p,omitempty"`
+	ResponseFormat   map[string]any  `json:"response_format,omitempty"`
+}
+
+type cohereMessage struct {
+	Role       string `json:"role"`
+	Content    any    `json:"content,omitempty"`
+	ToolCallId string `json:"tool_call_id,omitempty"`
+}
+
+// StringContent returns the text of the message, joining all text blocks when the content is a list of blocks.
+func (m *cohereMessage) StringContent() string {
+	content, ok := m.Content.(string)
+	if ok {
+		return content
+	}
+	contentList, ok := m.Content.([]any)
+	if ok {
+		var texts []string
+		for _, contentItem := range contentList {
+			contentMap, ok := contentItem.(map[string]any)
+			if !ok {
+				continue
+			}
+			if text, ok := contentMap[cohereContentTypeText].(string); ok {
+				texts = append(texts, text)
+			}
+		}
+		return strings.Join(texts, "\n")
+	}
+	return ""
+}
+
+type cohereChatResponse struct {
+	Id           string                `json:"id"`
+	FinishReason string                `json:"finish_reason"`
+	Message      cohereResponseMessage `json:"message"`
+	Usage        cohereUsage           `json:"usage"`
+}
+
+type cohereResponseMessage struct {
+	Role      string           `json:"role"`
+	Content   []cohereContent  `json:"content,omitempty"`
+	ToolPlan  string           `json:"tool_plan,omitempty"`
+	ToolCalls []cohereToolCall `json:"tool_calls,omitempty"`
+}
+
+type cohereContent struct {
+	Type string  `json:"type,omitempty"`
+	Text *string `json:"text,omitempty"`
+}
+
+type cohereToolCall struct {
+	Id       string              `json:"id,omitempty"`
+	Type     string              `json:"type,omitempty"`
+	Function *cohereFunctionCall `json:"function,omitempty"`
+}
+
+type cohereFunctionCall struct {
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments,omitempty"`
+}
+
+type cohereUsage struct {
+	BilledUnits cohereBilledUnits `json:"billed_units"`
+	Tokens      cohereTokens      `json:"tokens"`
+}
+
+type cohereBilledUnits struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+type cohereTokens struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// cohereStreamResponse is the payload of every event in the v2 chat streaming protocol.
+// Only the fields relevant to the event type are populated.
+type cohereStreamResponse struct {
+	Id    string             `json:"id,omitempty"`
+	Type  string             `json:"type"`
+	Index *int               `json:"index,omitempty"`
+	Delta *cohereStreamDelta `json:"delta,omitempty"`
+}
+
+type cohereStreamDelta struct {
+	Message      *cohereStreamMessage `json:"message,omitempty"`
+	FinishReason string               `json:"finish_reason,omitempty"`
+	Usage        *cohereUsage         `json:"usage,omitempty"`
+}
+
+type cohereStreamMessage struct {
+	Role      string          `json:"role,omitempty"`
+	Content   *cohereContent  `json:"content,omitempty"`
+	ToolPlan  string          `json:"tool_plan,omitempty"`
+	ToolCalls *cohereToolCall `json:"tool_calls,omitempty"`
+}
+
+type cohereErrorResponse struct {
+	Id      string `json:"id"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,359 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"llm-mock-server/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	textCompletionsPath  = "/v1/completions"
+	textCompletionMockId = "cmpl-llm-mock"
+
+	objectTextCompletion = "text_completion"
+
+	lengthReason = "length"
+
+	// textCompletionMockLogprob is the log probability of every token
+	textCompletionMockLogprob = -0.01
+)
+
+// textCompletionProvider emulates the legacy text completions API, where the mock tokenizes the text by runes.
+type textCompletionProvider struct{}
+
+func (p *textCompletionProvider) ShouldHandleRequest(ctx *gin.Context) bool {
+	context, _ := getRequestContext(ctx)
+	return context.Path == textCompletionsPath
+}
+
+func (p *textCompletionProvider) HandleChatCompletions(ctx *gin.Context) {
+	// Bind request body
+	var completionRequest textCompletionRequest
+	if err := ctx.ShouldBindJSON(&completionRequest); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate request body
+	if err := utils.Validate.Struct(completionRequest); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		for _, fieldError := range validationErrors {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fieldError.Error()})
+			return
+		}
+	}
+
+	prompts, err := completionRequest.prompts()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	n := max(completionRequest.N, 1)
+	bestOf := max(completionRequest.BestOf, n)
+	if completionRequest.BestOf != 0 && completionRequest.BestOf < n {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "best_of must be greater than or equal to n"})
+		return
+	}
+	if completionRequest.Stream && bestOf > n {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "best_of is not supported when streaming"})
+		return
+	}
+	if completionRequest.Echo && completionRequest.Suffix != "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "echo is not supported with suffix"})
+		return
+	}
+
+	// Each prompt gets n choices, all of which are the same as the mock is deterministic
+	var choices []textCompletionChoice
+	for _, prompt := range prompts {
+		text, finishReason := p.complete(prompt, completionRequest.MaxTokens)
+		for i := 0; i < n; i++ {
+			choices = append(choices, textCompletionChoice{
+				Index:        len(choices),
+				Text:         text,
+				FinishReason: ptr(finishReason),
+			})
+		}
+	}
+	// The best_of candidates are all generated and billed, but only n of them are returned
+	usage := usage{
+		PromptTokens:     completionMockUsage.PromptTokens * len(prompts),
+		CompletionTokens: completionMockUsage.CompletionTokens * len(prompts) * bestOf,
+	}
+	usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+
+	if completionRequest.Stream {
+		p.handleStreamResponse(ctx, completionRequest, prompts, choices, usage)
+	} else {
+		p.handleNonStreamResponse(ctx, completionRequest, prompts, choices, usage)
+	}
+}
+
+// complete returns the completion of the prompt, which is truncated to maxTokens runes if set.
+func (p *textCompletionProvider) complete(prompt string, maxTokens int) (string, string) {
+	response := []rune(prompt2Response(prompt))
+	if maxTokens > 0 && len(response) > maxTokens {
+		return string(response[:maxTokens]), lengthReason
+	}
+	return string(response), stopReason
+}
+
+func (p *textCompletionProvider) handleStreamResponse(ctx *gin.Context, completionRequest textCompletionRequest,
+	prompts []string, choices []textCompletionChoice, usage usage) {
+	utils.SetEventStreamHeaders(ctx)
+	dataChan := make(chan string)
+	stopChan := make(chan bool, 1)
+	n := len(choices) / len(prompts)
+
+	go func() {
+		send := func(choice textCompletionChoice) {
+			streamResponse := p.createResponse(completionRequest.Model, []textCompletionChoice{choice})
+			jsonStr, _ := json.Marshal(streamResponse)
+			dataChan <- string(jsonStr)
+		}
+
+		// The echoed prompt is sent in the first chunk of each choice
+		if completionRequest.Echo {
+			for i, choice := range choices {
+				prompt := prompts[i/n]
+				send(textCompletionChoice{
+					Index:    choice.Index,
+					Text:     prompt,
+					Logprobs: p.createLogprobs(completionRequest.Logprobs, prompt, 0, true),
+				})
+			}
+		}
+
+		textOffsets := make([]int, len(choices))
+		if completionRequest.Echo {
+			for i := range choices {
+				textOffsets[i] = len(prompts[i/n])
+			}
+		}
+		maxRunes := 0
+		for _, choice := range choices {
+			maxRunes = max(maxRunes, len([]rune(choice.Text)))
+		}
+		for r := 0; r < maxRunes; r++ {
+			for i, choice := range choices {
+				choiceRunes := []rune(choice.Text)
+				if r >= len(choiceRunes) {
+					continue
+				}
+				s := string(choiceRunes[r])
+				streamChoice := textCompletionChoice{
+					Index:    choice.Index,
+					Text:     s,
+					Logprobs: p.createLogprobs(completionRequest.Logprobs, s, textOffsets[i], false),
+				}
+				textOffsets[i] += len(s)
+				if r == len(choiceRunes)-1 {
+					streamChoice.FinishReason = choice.FinishReason
+				}
+				send(streamChoice)
+			}
+
+			// Simulate response delay
+			time.Sleep(200 * time.Millisecond)
+		}
+
+		// Choices without any text still end with a chunk carrying the finish reason
+		for i, choice := range choices {
+			if choice.Text == "" {
+				send(textCompletionChoice{
+					Index:        choice.Index,
+					Logprobs:     p.createLogprobs(completionRequest.Logprobs, "", textOffsets[i], false),
+					FinishReason: choice.FinishReason,
+				})
+			}
+		}
+
+		if completionRequest.StreamOptions != nil && completionRequest.StreamOptions.IncludeUsage {
+			usageResponse := p.createResponse(completionRequest.Model, []textCompletionChoice{})
+			usageResponse.Usage = &usage
+			jsonStr, _ := json.Marshal(usageResponse)
+			dataChan <- string(jsonStr)
+		}
+		stopChan <- true
+	}()
+
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case data := <-dataChan:
+			ctx.Render(-1, streamEvent{Data: "data: " + data})
+			return true
+		case <-stopChan:
+			ctx.Render(-1, streamEvent{Data: "data: [DONE]"})
+			return false
+		}
+	})
+}
+
+func (p *textCompletionProvider) handleNonStreamResponse(ctx *gin.Context, completionRequest textCompletionRequest,
+	prompts []string, choices []textCompletionChoice, usage usage) {
+	n := len(choices) / len(prompts)
+	for i := range choices {
+		prompt := prompts[i/n]
+		text := choices[i].Text
+		offset := 0
+		if completionRequest.Echo {
+			choices[i].Text = prompt + text
+			offset = len(prompt)
+		}
+		if completionRequest.Logprobs != nil {
+			logprobs := p.createLogprobs(completionRequest.Logprobs, text, offset, false)
+			if completionRequest.Echo {
+				promptLogprobs := p.createLogprobs(completionRequest.Logprobs, prompt, 0, true)
+				logprobs.Tokens = append(promptLogprobs.Tokens, logprobs.Tokens...)
+				logprobs.TokenLogprobs = append(promptLogprobs.TokenLogprobs, logprobs.TokenLogprobs...)
+				logprobs.TopLogprobs = append(promptLogprobs.TopLogprobs, logprobs.TopLogprobs...)
+				logprobs.TextOffset = append(promptLogprobs.TextOffset, logprobs.TextOffset...)
+			}
+			choices[i].Logprobs = logprobs
+		}
+	}
+	completion := p.createResponse(completionRequest.Model, choices)
+	completion.Usage = &usage
+	ctx.JSON(http.StatusOK, completion)
+}
+
+func (p *textCompletionProvider) createResponse(model string, choices []textCompletionChoice) textCompletionResponse {
+	return textCompletionResponse{
+		Id:      textCompletionMockId,
+		Object:  objectTextCompletion,
+		Created: completionMockCreated,
+		Model:   model,
+		Choices: choices,
+	}
+}
+
+// createLogprobs returns the log probabilities of each rune in the text, starting at the given offset,
+// or nil if they are not requested. The first token of the prompt has no log probability.
+func (p *textCompletionProvider) createLogprobs(logprobs *int, text string, offset int, isPrompt bool) *textCompletionLogprobs {
+	if logprobs == nil {
+		return nil
+	}
+	result := &textCompletionLogprobs{
+		Tokens:        []string{},
+		TokenLogprobs: []*float64{},
+		TopLogprobs:   []map[string]float64{},
+		TextOffset:    []int{},
+	}
+	for i, s := range text {
+		token := string(s)
+		result.Tokens = append(result.Tokens, token)
+		result.TextOffset = append(result.TextOffset, offset+i)
+		if isPrompt && i == 0 {
+			result.TokenLogprobs = append(result.TokenLogprobs, nil)
+			result.TopLogprobs = append(result.TopLogprobs, nil)
+			continue
+		}
+		result.TokenLogprobs = append(result.TokenLogprobs, ptr(textCompletionMockLogprob))
+		topLogprobs := map[string]float64{}
+		if *logprobs > 0 {
+			topLogprobs[token] = textCompletionMockLogprob
+		}
+		result.TopLogprobs = append(result.TopLogprobs, topLogprobs)
+	}
+	return result
+}
+
+type textCompletionRequest struct {
+	Model            string         `json:"model" validate:"required"`
+	Prompt           any            `json:"prompt" validate:"required"`
+	Suffix           string         `json:"suffix,omitempty"`
+	MaxTokens        int            `json:"max_tokens,omitempty"`
+	Temperature      float64        `json:"temperature,omitempty"`
+	TopP             float64        `json:"top_p,omitempty"`
+	N                int            `json:"n,omitempty" validate:"min=0,max=128"`
+	Stream           bool           `json:"stream,omitempty"`
+	StreamOptions    *streamOptions `json:"stream_options,omitempty"`
+	Logprobs         *int           `json:"logprobs,omitempty" validate:"omitempty,min=0,max=5"`
+	Echo             bool           `json:"echo,omitempty"`
+	Stop             any            `json:"stop,omitempty"`
+	PresencePenalty  float64        `json:"presence_penalty,omitempty"`
+	FrequencyPenalty float64        `json:"frequency_penalty,omitempty"`
+	BestOf           int            `json:"best_of,omitempty" validate:"min=0,max=20"`
+	LogitBias        map[string]any `json:"logit_bias,omitempty"`
+	Seed             int            `json:"seed,omitempty"`
+	User             string         `json:"user,omitempty"`
+}
+
+// prompts returns the prompts, which are either a string, a list of strings, a list of tokens or a list of token lists.
+// The mock cannot detokenize, so a token list is treated as the text of the space-separated token IDs.
+func (r *textCompletionRequest) prompts() ([]string, error) {
+	if prompt, ok := r.Prompt.(string); ok {
+		return []string{prompt}, nil
+	}
+	items, ok := r.Prompt.([]any)
+	if !ok || len(items) == 0 {
+		return nil, fmt.Errorf("prompt must be a string, a list of strings, a list of tokens or a list of token lists")
+	}
+	if _, ok := items[0].(float64); ok {
+		prompt, err := tokensToPrompt(items)
+		if err != nil {
+			return nil, err
+		}
+		return []string{prompt}, nil
+	}
+	var prompts []string
+	for _, item := range items {
+		switch item := item.(type) {
+		case string:
+			prompts = append(prompts, item)
+		case []any:
+			prompt, err := tokensToPrompt(item)
+			if err != nil {
+				return nil, err
+			}
+			prompts = append(prompts, prompt)
+		default:
+			return nil, fmt.Errorf("prompt must be a string, a list of strings, a list of tokens or a list of token lists")
+		}
+	}
+	return prompts, nil
+}
+
+func tokensToPrompt(tokens []any) (string, error) {
+	var tokenIds []string
+	for _, token := range tokens {
+		tokenId, ok := token.(float64)
+		if !ok {
+			return "", fmt.Errorf("prompt tokens must be integers")
+		}
+		tokenIds = append(tokenIds, fmt.Sprint(int(tokenId)))
+	}
+	return strings.Join(tokenIds, " "), nil
+}
+
+type textCompletionResponse struct {
+	Id                string                 `json:"id"`
+	Object            string                 `json:"object"`
+	Created           int64                  `json:"created"`
+	Model             string                 `json:"model"`
+	SystemFingerprint string                 `json:"system_fingerprint,omitempty"`
+	Choices           []textCompletionChoice `json:"choices"`
+	Usage             *usage                 `json:"usage,omitempty"`
+}
+
+type textCompletionChoice struct {
+	Index        int                     `json:"index"`
+	Text         string                  `json:"text"`
+	Logprobs     *textCompletionLogprobs `json:"logprobs"`
+	FinishReason *string                 `json:"finish_reason"`
+}
+
+type textCompletionLogprobs struct {
+	Tokens        []string             `json:"tokens"`
+	TokenLogprobs []*float64           `json:"token_logprobs"`
+	TopLogprobs   []map[string]float64 `json:"top_logprobs"`
+	TextOffset    []int                `json:"text_offset"`
+}
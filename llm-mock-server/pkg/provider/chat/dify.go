@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"llm-mock-server/pkg/utils"
@@ -14,19 +15,59 @@ import (
 )
 
 const (
-	difyDomain         = "api.dify.ai"
-	difyChatPath       = "/v1/chat-messages"
-	difyCompletionPath = "/v1/completion-messages"
-	botTypeCompletion  = "Completion"
-	botTypeChat        = "Chat"
+	difyDomain          = "api.dify.ai"
+	difyChatPath        = "/v1/chat-messages"
+	difyCompletionPath  = "/v1/completion-messages"
+	difyWorkflowRunPath = "/v1/workflows/run"
+
+	// difyAppTypeChat, difyAppTypeAgent and difyAppTypeWorkflow are the app types which can be encoded in the API key
+	// like "app-agent-xxx" or configured as the default.
+	difyAppTypeChat     = "chat"
+	difyAppTypeAgent    = "agent"
+	difyAppTypeWorkflow = "workflow"
+	difyApiKeyPrefix    = "app-"
+
+	difyModeCompletion   = "completion"
+	difyModeChat         = "chat"
+	difyModeAgentChat    = "agent-chat"
+	difyModeAdvancedChat = "advanced-chat"
+
+	difyEventMessage          = "message"
+	difyEventAgentMessage     = "agent_message"
+	difyEventAgentThought     = "agent_thought"
+	difyEventMessageEnd       = "message_end"
+	difyEventWorkflowStarted  = "workflow_started"
+	difyEventNodeStarted      = "node_started"
+	difyEventNodeFinished     = "node_finished"
+	difyEventTextChunk        = "text_chunk"
+	difyEventWorkflowFinished = "workflow_finished"
+	difyEventPing             = "ping"
+
+	difyResponseModeStreaming = "streaming"
+	difyStatusSucceeded       = "succeeded"
+
+	difyMockWorkflowId      = "workflow-llm-mock"
+	difyMockWorkflowRunId   = "workflow-run-llm-mock"
+	difyMockAgentThoughtId  = "agent-thought-llm-mock"
+	difyMockNodeElapsedTime = 0.5
+)
+
+var (
+	difyStartNode  = difyMockNode{Id: "start", Type: "start", Title: "Start"}
+	difyLlmNode    = difyMockNode{Id: "llm", Type: "llm", Title: "LLM", OutputKey: "text"}
+	difyAnswerNode = difyMockNode{Id: "answer", Type: "answer", Title: "Answer", OutputKey: "answer"}
+	difyEndNode    = difyMockNode{Id: "end", Type: "end", Title: "End", OutputKey: "text"}
 )
 
 type difyProvider struct {
+	// defaultAppType is the app type used when the API key does not encode one.
+	defaultAppType string
 }
 
 func (p *difyProvider) ShouldHandleRequest(ctx *gin.Context) bool {
 	context, _ := getRequestContext(ctx)
-	return context.Host == difyDomain && (context.Path == difyChatPath || context.Path == difyCompletionPath)
+	return context.Host == difyDomain &&
+		(context.Path == difyChatPath || context.Path == difyCompletionPath || context.Path == difyWorkflowRunPath)
 }
 
 func (p *difyProvider) HandleChatCompletions(ctx *gin.Context) {
@@ -36,6 +77,12 @@ func (p *difyProvider) HandleChatCompletions(ctx *gin.Context) {
 		p.sendErrorResponse(ctx, 401, "Unauthorized: Please provide an API key")
 		return
 	}
+	appType := p.appType(authHeader)
+
+	if ctx.Request.URL.Path == difyWorkflowRunPath {
+		p.handleWorkflowRun(ctx, appType)
+		return
+	}
 
 	// Bind request body
 	var chatRequest difyChatRequest
@@ -55,9 +102,10 @@ func (p *difyProvider) HandleChatCompletions(ctx *gin.Context) {
 
 	// Generate reply based on the query
 	reply := prompt2Response(chatRequest.Query)
-	botType := botTypeChat
-	if ctx.Request.URL.Path == difyCompletionPath {
-		botType = botTypeCompletion
+	var mode string
+	switch {
+	case ctx.Request.URL.Path == difyCompletionPath:
+		mode = difyModeCompletion
 		query, ok := chatRequest.Inputs["query"]
 		if !ok {
 			p.sendErrorResponse(ctx, 400, "Invalid request: query is required for bot type completion")
@@ -70,13 +118,106 @@ func (p *difyProvider) HandleChatCompletions(ctx *gin.Context) {
 			p.sendErrorResponse(ctx, 400, "Invalid request: query must be a string for bot type completion")
 			return
 		}
+	case appType == difyAppTypeAgent:
+		mode = difyModeAgentChat
+	case appType == difyAppTypeWorkflow:
+		// A workflow app serving chat messages is a chatflow, i.e. an advanced chat app
+		mode = difyModeAdvancedChat
+	default:
+		mode = difyModeChat
+	}
+
+	conversationId := chatRequest.ConversationId
+	if conversationId == "" {
+		conversationId = completionMockId
 	}
 
 	// Handle stream or non-stream response based on the request
-	if chatRequest.ResponseMode == "streaming" {
-		p.handleStreamResponse(ctx, chatRequest, botType, reply)
+	if chatRequest.ResponseMode == difyResponseModeStreaming {
+		p.handleStreamResponse(ctx, func(dataChan chan<- streamEvent) {
+			switch mode {
+			case difyModeAgentChat:
+				p.streamAgentChat(dataChan, conversationId, reply)
+			case difyModeAdvancedChat:
+				p.streamAdvancedChat(dataChan, conversationId, chatRequest.Inputs, reply)
+			default:
+				p.streamChat(dataChan, conversationId, reply)
+			}
+		})
+	} else {
+		p.handleNonStreamResponse(ctx, mode, conversationId, reply)
+	}
+}
+
+// appType returns the app type encoded in the API key like "app-agent-xxx", or the default app type otherwise.
+func (p *difyProvider) appType(authHeader string) string {
+	apiKey := strings.TrimPrefix(authHeader, "Bearer ")
+	for _, appType := range []string{difyAppTypeChat, difyAppTypeAgent, difyAppTypeWorkflow} {
+		if strings.HasPrefix(apiKey, difyApiKeyPrefix+appType+"-") {
+			return appType
+		}
+	}
+	return p.defaultAppType
+}
+
+func (p *difyProvider) handleWorkflowRun(ctx *gin.Context, appType string) {
+	if appType != difyAppTypeWorkflow {
+		p.sendErrorResponse(ctx, 400, "Please check if your Workflow app mode matches the right API route.")
+		return
+	}
+
+	// Bind request body
+	var workflowRequest difyWorkflowRequest
+	if err := ctx.ShouldBindJSON(&workflowRequest); err != nil {
+		p.sendErrorResponse(ctx, 400, fmt.Sprintf("Invalid request: %v", err.Error()))
+		return
+	}
+
+	// Validate request body
+	if err := utils.Validate.Struct(workflowRequest); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		for _, fieldError := range validationErrors {
+			p.sendErrorResponse(ctx, 400, fmt.Sprintf("Invalid request: %v", fieldError.Error()))
+			return
+		}
+	}
+
+	// The reply is generated from the query input, or from all the inputs if there is no query
+	query, ok := workflowRequest.Inputs["query"].(string)
+	if !ok {
+		inputs, _ := json.Marshal(workflowRequest.Inputs)
+		query = string(inputs)
+	}
+	reply := prompt2Response(query)
+
+	if workflowRequest.ResponseMode == difyResponseModeStreaming {
+		p.handleStreamResponse(ctx, func(dataChan chan<- streamEvent) {
+			// Workflow runs may take long, so a ping is sent to keep the connection alive
+			dataChan <- streamEvent{Event: difyEventPing, Data: ""}
+			envelope := difyWorkflowEvent{TaskId: completionMockId, WorkflowRunId: difyMockWorkflowRunId}
+			p.streamWorkflow(dataChan, envelope, difyEndNode, workflowRequest.Inputs, reply, func() {
+				for _, s := range reply {
+					p.sendEvent(dataChan, difyWorkflowEvent{
+						Event:         difyEventTextChunk,
+						TaskId:        completionMockId,
+						WorkflowRunId: difyMockWorkflowRunId,
+						Data: difyTextChunkData{
+							Text:                 string(s),
+							FromVariableSelector: []string{difyLlmNode.Id, difyLlmNode.OutputKey},
+						},
+					})
+
+					// Simulate response delay
+					time.Sleep(200 * time.Millisecond)
+				}
+			})
+		})
 	} else {
-		p.handleNonStreamResponse(ctx, chatRequest, botType, reply)
+		ctx.JSON(http.StatusOK, difyWorkflowRunResponse{
+			WorkflowRunId: difyMockWorkflowRunId,
+			TaskId:        completionMockId,
+			Data:          p.createWorkflowData(map[string]any{difyEndNode.OutputKey: reply}),
+		})
 	}
 }
 
@@ -90,56 +231,182 @@ func (p *difyProvider) sendErrorResponse(ctx *gin.Context, statusCode int, messa
 	})
 }
 
-func (p *difyProvider) handleStreamResponse(ctx *gin.Context, chatRequest difyChatRequest, botType string, reply string) {
+// handleStreamResponse streams the events sent by the stream function to the client.
+func (p *difyProvider) handleStreamResponse(ctx *gin.Context, stream func(dataChan chan<- streamEvent)) {
 	utils.SetEventStreamHeaders(ctx)
-	dataChan := make(chan string)
+	dataChan := make(chan streamEvent)
 	stopChan := make(chan bool, 1)
 
 	go func() {
-		for _, s := range reply {
-			response := difyChunkChatResponse{
-				Event:          "agent_thought",
-				Answer:         string(s),
-				ConversationId: completionMockId,
-				MessageId:      completionMockId,
-				CreatedAt:      completionMockCreated,
-			}
-			jsonStr, _ := json.Marshal(response)
-			dataChan <- string(jsonStr)
-
-			// Simulate response delay
-			time.Sleep(200 * time.Millisecond)
-		}
+		stream(dataChan)
 		stopChan <- true
 	}()
 
 	ctx.Stream(func(w io.Writer) bool {
 		select {
-		case data := <-dataChan:
-			ctx.Render(-1, streamEvent{Data: "data: " + data})
+		case event := <-dataChan:
+			ctx.Render(-1, event)
 			return true
 		case <-stopChan:
-			// Send final message with metadata
-			finalResponse := difyChunkChatResponse{
-				Event:          "message_end",
-				Answer:         reply,
-				ConversationId: completionMockId,
-				MessageId:      completionMockId,
-				MetaData: difyMetaData{
-					Usage: completionMockUsage,
-				},
-			}
-			jsonStr, _ := json.Marshal(finalResponse)
-			ctx.Render(-1, streamEvent{Data: fmt.Sprintf("data: %s", jsonStr)})
 			return false
 		}
 	})
 }
 
-func (p *difyProvider) handleNonStreamResponse(ctx *gin.Context, chatRequest difyChatRequest, botType string, reply string) {
+func (p *difyProvider) sendEvent(dataChan chan<- streamEvent, event any) {
+	jsonStr, _ := json.Marshal(event)
+	dataChan <- streamEvent{Data: fmt.Sprintf("data: %s", jsonStr)}
+}
+
+// streamChat streams the events of chat and completion apps, which send the answer in message events.
+func (p *difyProvider) streamChat(dataChan chan<- streamEvent, conversationId string, reply string) {
+	p.streamMessages(dataChan, difyEventMessage, conversationId, reply)
+	p.sendMessageEnd(dataChan, conversationId)
+}
+
+// streamAgentChat streams the events of agent apps, which send the answer in agent_message events
+// between the agent_thought events reporting the start and the result of the thought.
+func (p *difyProvider) streamAgentChat(dataChan chan<- streamEvent, conversationId string, reply string) {
+	thought := difyAgentThoughtEvent{
+		Event:          difyEventAgentThought,
+		TaskId:         completionMockId,
+		Id:             difyMockAgentThoughtId,
+		MessageId:      completionMockId,
+		ConversationId: conversationId,
+		Position:       1,
+		MessageFiles:   []string{},
+		CreatedAt:      completionMockCreated,
+	}
+	p.sendEvent(dataChan, thought)
+	p.streamMessages(dataChan, difyEventAgentMessage, conversationId, reply)
+	thought.Thought = reply
+	p.sendEvent(dataChan, thought)
+	p.sendMessageEnd(dataChan, conversationId)
+}
+
+// streamAdvancedChat streams the events of chatflow apps, which send the answer in message events
+// while running the workflow.
+func (p *difyProvider) streamAdvancedChat(dataChan chan<- streamEvent, conversationId string, inputs map[string]any, reply string) {
+	envelope := difyWorkflowEvent{
+		TaskId:         completionMockId,
+		WorkflowRunId:  difyMockWorkflowRunId,
+		ConversationId: conversationId,
+		MessageId:      completionMockId,
+	}
+	p.streamWorkflow(dataChan, envelope, difyAnswerNode, inputs, reply, func() {
+		p.streamMessages(dataChan, difyEventMessage, conversationId, reply)
+	})
+	p.sendMessageEnd(dataChan, conversationId)
+}
+
+func (p *difyProvider) streamMessages(dataChan chan<- streamEvent, event string, conversationId string, reply string) {
+	for _, s := range reply {
+		p.sendEvent(dataChan, difyChunkChatResponse{
+			Event:          event,
+			TaskId:         completionMockId,
+			Id:             completionMockId,
+			ConversationId: conversationId,
+			MessageId:      completionMockId,
+			Answer:         string(s),
+			CreatedAt:      completionMockCreated,
+		})
+
+		// Simulate response delay
+		time.Sleep(200 * time.Millisecond)
+	}
+}
+
+func (p *difyProvider) sendMessageEnd(dataChan chan<- streamEvent, conversationId string) {
+	p.sendEvent(dataChan, difyChunkChatResponse{
+		Event:          difyEventMessageEnd,
+		TaskId:         completionMockId,
+		Id:             completionMockId,
+		ConversationId: conversationId,
+		MessageId:      completionMockId,
+		MetaData: &difyMetaData{
+			Usage: completionMockUsage,
+		},
+	})
+}
+
+// streamWorkflow streams the events of a workflow running the start, LLM and last nodes in order.
+// The LLM node generates the reply by calling streamText, which is then output by the last node.
+func (p *difyProvider) streamWorkflow(dataChan chan<- streamEvent, envelope difyWorkflowEvent, lastNode difyMockNode,
+	inputs map[string]any, reply string, streamText func()) {
+	envelope.Event = difyEventWorkflowStarted
+	envelope.Data = difyWorkflowData{
+		Id:             difyMockWorkflowRunId,
+		WorkflowId:     difyMockWorkflowId,
+		SequenceNumber: 1,
+		Inputs:         inputs,
+		CreatedAt:      completionMockCreated,
+	}
+	p.sendEvent(dataChan, envelope)
+
+	predecessorNodeId := ""
+	for i, node := range []difyMockNode{difyStartNode, difyLlmNode, lastNode} {
+		nodeData := difyNodeData{
+			Id:                fmt.Sprintf("%s-%s", difyMockWorkflowRunId, node.Id),
+			NodeId:            node.Id,
+			NodeType:          node.Type,
+			Title:             node.Title,
+			Index:             i + 1,
+			PredecessorNodeId: predecessorNodeId,
+			CreatedAt:         completionMockCreated,
+		}
+		if node == difyStartNode {
+			nodeData.Inputs = inputs
+		}
+		envelope.Event = difyEventNodeStarted
+		envelope.Data = nodeData
+		p.sendEvent(dataChan, envelope)
+
+		if node == difyStartNode {
+			nodeData.Outputs = inputs
+		} else {
+			if node == difyLlmNode {
+				streamText()
+				nodeData.ExecutionMetadata = &difyExecutionMetadata{TotalTokens: completionMockUsage.TotalTokens}
+			}
+			nodeData.Outputs = map[string]any{node.OutputKey: reply}
+		}
+		nodeData.Status = difyStatusSucceeded
+		nodeData.ElapsedTime = difyMockNodeElapsedTime
+		nodeData.FinishedAt = completionMockCreated
+		envelope.Event = difyEventNodeFinished
+		envelope.Data = nodeData
+		p.sendEvent(dataChan, envelope)
+
+		predecessorNodeId = node.Id
+	}
+
+	envelope.Event = difyEventWorkflowFinished
+	envelope.Data = p.createWorkflowData(map[string]any{lastNode.OutputKey: reply})
+	p.sendEvent(dataChan, envelope)
+}
+
+func (p *difyProvider) createWorkflowData(outputs map[string]any) difyWorkflowData {
+	return difyWorkflowData{
+		Id:          difyMockWorkflowRunId,
+		WorkflowId:  difyMockWorkflowId,
+		Status:      difyStatusSucceeded,
+		Outputs:     outputs,
+		ElapsedTime: 3 * difyMockNodeElapsedTime,
+		TotalTokens: completionMockUsage.TotalTokens,
+		TotalSteps:  3,
+		CreatedAt:   completionMockCreated,
+		FinishedAt:  completionMockCreated,
+	}
+}
+
+func (p *difyProvider) handleNonStreamResponse(ctx *gin.Context, mode string, conversationId string, reply string) {
 	response := difyChatResponse{
+		Event:          difyEventMessage,
+		TaskId:         completionMockId,
+		Id:             completionMockId,
+		Mode:           mode,
 		Answer:         reply,
-		ConversationId: chatRequest.ConversationId,
+		ConversationId: conversationId,
 		MessageId:      completionMockId,
 		CreatedAt:      completionMockCreated,
 		MetaData: difyMetaData{
@@ -149,6 +416,14 @@ func (p *difyProvider) handleNonStreamResponse(ctx *gin.Context, chatRequest dif
 	ctx.JSON(http.StatusOK, response)
 }
 
+type difyMockNode struct {
+	Id    string
+	Type  string
+	Title string
+	// OutputKey is the name of the variable the node outputs the reply to.
+	OutputKey string
+}
+
 type difyChatRequest struct {
 	Inputs           map[string]interface{} `json:"inputs"`
 	Query            string                 `json:"query"`
@@ -158,31 +433,107 @@ type difyChatRequest struct {
 	ConversationId   string                 `json:"conversation_id"`
 }
 
-type difyMetaData struct {
-	Usage usage `json:"usage"`
+type difyWorkflowRequest struct {
+	Inputs       map[string]interface{} `json:"inputs" validate:"required"`
+	ResponseMode string                 `json:"response_mode"`
+	User         string                 `json:"user"`
 }
 
-type difyData struct {
-	WorkflowId string                 `json:"workflow_id"`
-	Id         string                 `json:"id"`
-	Outputs    map[string]interface{} `json:"outputs"`
+type difyMetaData struct {
+	Usage usage `json:"usage"`
 }
 
 type difyChatResponse struct {
+	Event          string       `json:"event"`
+	TaskId         string       `json:"task_id"`
+	Id             string       `json:"id"`
 	ConversationId string       `json:"conversation_id"`
 	MessageId      string       `json:"message_id"`
+	Mode           string       `json:"mode"`
 	Answer         string       `json:"answer"`
 	CreatedAt      int64        `json:"created_at"`
-	Data           difyData     `json:"data"`
 	MetaData       difyMetaData `json:"metadata"`
 }
 
+// difyChunkChatResponse is the chunk of the message, agent_message and message_end events.
 type difyChunkChatResponse struct {
-	Event          string       `json:"event"`
-	ConversationId string       `json:"conversation_id"`
-	MessageId      string       `json:"message_id"`
-	Answer         string       `json:"answer"`
-	CreatedAt      int64        `json:"created_at"`
-	Data           difyData     `json:"data"`
-	MetaData       difyMetaData `json:"metadata"`
+	Event          string        `json:"event"`
+	TaskId         string        `json:"task_id"`
+	Id             string        `json:"id"`
+	ConversationId string        `json:"conversation_id"`
+	MessageId      string        `json:"message_id"`
+	Answer         string        `json:"answer,omitempty"`
+	CreatedAt      int64         `json:"created_at,omitempty"`
+	MetaData       *difyMetaData `json:"metadata,omitempty"`
+}
+
+type difyAgentThoughtEvent struct {
+	Event          string   `json:"event"`
+	TaskId         string   `json:"task_id"`
+	Id             string   `json:"id"`
+	MessageId      string   `json:"message_id"`
+	ConversationId string   `json:"conversation_id"`
+	Position       int      `json:"position"`
+	Thought        string   `json:"thought"`
+	Observation    string   `json:"observation"`
+	Tool           string   `json:"tool"`
+	ToolInput      string   `json:"tool_input"`
+	MessageFiles   []string `json:"message_files"`
+	CreatedAt      int64    `json:"created_at"`
+}
+
+// difyWorkflowEvent is the envelope of the workflow events, whose data depends on the event.
+type difyWorkflowEvent struct {
+	Event          string `json:"event"`
+	TaskId         string `json:"task_id"`
+	WorkflowRunId  string `json:"workflow_run_id"`
+	ConversationId string `json:"conversation_id,omitempty"`
+	MessageId      string `json:"message_id,omitempty"`
+	Data           any    `json:"data"`
+}
+
+type difyWorkflowData struct {
+	Id             string         `json:"id"`
+	WorkflowId     string         `json:"workflow_id"`
+	SequenceNumber int            `json:"sequence_number,omitempty"`
+	Inputs         map[string]any `json:"inputs,omitempty"`
+	Status         string         `json:"status,omitempty"`
+	Outputs        map[string]any `json:"outputs,omitempty"`
+	Error          *string        `json:"error,omitempty"`
+	ElapsedTime    float64        `json:"elapsed_time,omitempty"`
+	TotalTokens    int            `json:"total_tokens,omitempty"`
+	TotalSteps     int            `json:"total_steps,omitempty"`
+	CreatedAt      int64          `json:"created_at"`
+	FinishedAt     int64          `json:"finished_at,omitempty"`
+}
+
+type difyNodeData struct {
+	Id                string                 `json:"id"`
+	NodeId            string                 `json:"node_id"`
+	NodeType          string                 `json:"node_type"`
+	Title             string                 `json:"title"`
+	Index             int                    `json:"index"`
+	PredecessorNodeId string                 `json:"predecessor_node_id,omitempty"`
+	Inputs            map[string]any         `json:"inputs,omitempty"`
+	Outputs           map[string]any         `json:"outputs,omitempty"`
+	Status            string                 `json:"status,omitempty"`
+	ElapsedTime       float64                `json:"elapsed_time,omitempty"`
+	ExecutionMetadata *difyExecutionMetadata `json:"execution_metadata,omitempty"`
+	CreatedAt         int64                  `json:"created_at"`
+	FinishedAt        int64                  `json:"finished_at,omitempty"`
+}
+
+type difyExecutionMetadata struct {
+	TotalTokens int `json:"total_tokens"`
+}
+
+type difyTextChunkData struct {
+	Text                 string   `json:"text"`
+	FromVariableSelector []string `json:"from_variable_selector"`
+}
+
+type difyWorkflowRunResponse struct {
+	WorkflowRunId string           `json:"workflow_run_id"`
+	TaskId        string           `json:"task_id"`
+	Data          difyWorkflowData `json:"data"`
 }
@@ -0,0 +1,285 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"llm-mock-server/pkg/provider"
+	"llm-mock-server/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	geminiDomain = "generativelanguage.googleapis.com"
+
+	geminiActionGenerateContent       = "generateContent"
+	geminiActionStreamGenerateContent = "streamGenerateContent"
+
+	geminiRoleModel             = "model"
+	geminiFinishReasonStop      = "STOP"
+	geminiProbabilityNegligible = "NEGLIGIBLE"
+	geminiAltSSE                = "sse"
+
+	geminiStatusInvalidArgument = "INVALID_ARGUMENT"
+	geminiStatusNotFound        = "NOT_FOUND"
+)
+
+var (
+	// geminiModelPathPrefixes lists the API versions whose model paths are in the form of "{prefix}{model}:{action}".
+	geminiModelPathPrefixes = []string{
+		"/v1beta/models/",
+		"/v1/models/",
+	}
+
+	geminiHarmCategories = []string{
+		"HARM_CATEGORY_SEXUALLY_EXPLICIT",
+		"HARM_CATEGORY_HATE_SPEECH",
+		"HARM_CATEGORY_HARASSMENT",
+		"HARM_CATEGORY_DANGEROUS_CONTENT",
+	}
+)
+
+type geminiProvider struct {
+	auth *provider.GoogleAuth
+}
+
+func (p *geminiProvider) ShouldHandleRequest(ctx *gin.Context) bool {
+	context, _ := getRequestContext(ctx)
+	if context.Host != geminiDomain {
+		return false
+	}
+	_, action := parseGeminiModelPath(context.Path)
+	return action == geminiActionGenerateContent || action == geminiActionStreamGenerateContent
+}
+
+// registerRoutes registers the chat actions of the model routes, which are shared with the embedding actions.
+func (p *geminiProvider) registerRoutes(server *gin.Engine) {
+	provider.HandleModelActions(server, provider.GeminiModelRoutes,
+		[]string{geminiActionGenerateContent, geminiActionStreamGenerateContent}, handleChatCompletions)
+}
+
+// parseGeminiModelPath splits a path like "/v1beta/models/gemini-pro:generateContent" into the model and the action.
+func parseGeminiModelPath(path string) (string, string) {
+	for _, prefix := range geminiModelPathPrefixes {
+		if !strings.HasPrefix(path, prefix) {
+			continue
+		}
+		model, action, found := strings.Cut(strings.TrimPrefix(path, prefix), ":")
+		if !found {
+			return "", ""
+		}
+		return model, action
+	}
+	return "", ""
+}
+
+func (p *geminiProvider) HandleChatCompletions(ctx *gin.Context) {
+	// Validate API key
+	if !p.auth.AuthenticateGemini(ctx) {
+		return
+	}
+
+	context, _ := getRequestContext(ctx)
+	model, action := parseGeminiModelPath(context.Path)
+	p.handleGenerateContent(ctx, model, action)
+}
+
+// handleGenerateContent handles the authenticated generateContent or streamGenerateContent request, which may also be
+// sent to Vertex AI.
+func (p *geminiProvider) handleGenerateContent(ctx *gin.Context, model, action string) {
+	// Bind request body
+	var chatRequest geminiGenerateContentRequest
+	if err := ctx.ShouldBindJSON(&chatRequest); err != nil {
+		p.sendErrorResponse(ctx, http.StatusBadRequest, geminiStatusInvalidArgument,
+			fmt.Sprintf("Invalid JSON payload received. %v", err.Error()))
+		return
+	}
+
+	// Validate request body
+	if err := utils.Validate.Struct(chatRequest); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		for _, fieldError := range validationErrors {
+			p.sendErrorResponse(ctx, http.StatusBadRequest, geminiStatusInvalidArgument,
+				fmt.Sprintf("Invalid request: %v", fieldError.Error()))
+			return
+		}
+	}
+
+	contents := chatRequest.Contents
+	prompt := contents[len(contents)-1].StringContent()
+	response := prompt2Response(prompt)
+
+	if action == geminiActionStreamGenerateContent {
+		p.handleStreamResponse(ctx, chatRequest, model, response)
+	} else {
+		p.handleNonStreamResponse(ctx, chatRequest, model, response)
+	}
+}
+
+func (p *geminiProvider) sendErrorResponse(ctx *gin.Context, statusCode int, status, message string) {
+	provider.SendGoogleErrorResponse(ctx, statusCode, status, message)
+}
+
+func (p *geminiProvider) handleStreamResponse(ctx *gin.Context, chatRequest geminiGenerateContentRequest, model, response string) {
+	// Gemini streams Server-Sent Events with alt=sse, or else a JSON array whose elements arrive incrementally.
+	isSSE := ctx.Query("alt") == geminiAltSSE
+	if isSSE {
+		utils.SetEventStreamHeaders(ctx)
+	} else {
+		ctx.Writer.Header().Set("Content-Type", "application/json")
+	}
+	dataChan := make(chan string)
+	stopChan := make(chan bool, 1)
+
+	go func() {
+		send := func(streamResponse geminiGenerateContentResponse) {
+			jsonStr, _ := json.Marshal(streamResponse)
+			dataChan <- string(jsonStr)
+		}
+
+		for _, s := range response {
+			streamResponse := p.createGenerateContentResponse(chatRequest, model, string(s))
+			for j := range streamResponse.Candidates {
+				streamResponse.Candidates[j].FinishReason = ""
+			}
+			send(streamResponse)
+
+			// Simulate response delay
+			time.Sleep(200 * time.Millisecond)
+		}
+
+		// The last chunk carries the finish reason and the usage even if the response is empty
+		send(p.createGenerateContentResponse(chatRequest, model, ""))
+		stopChan <- true
+	}()
+
+	first := true
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case data := <-dataChan:
+			if isSSE {
+				ctx.Render(-1, streamEvent{Data: "data: " + data})
+			} else if first {
+				_, _ = w.Write([]byte("[" + data))
+			} else {
+				_, _ = w.Write([]byte(",\r\n" + data))
+			}
+			first = false
+			return true
+		case <-stopChan:
+			if !isSSE {
+				if first {
+					_, _ = w.Write([]byte("["))
+				}
+				_, _ = w.Write([]byte("]"))
+			}
+			return false
+		}
+	})
+}
+
+func (p *geminiProvider) handleNonStreamResponse(ctx *gin.Context, chatRequest geminiGenerateContentRequest, model, response string) {
+	ctx.JSON(http.StatusOK, p.createGenerateContentResponse(chatRequest, model, response))
+}
+
+func (p *geminiProvider) createGenerateContentResponse(chatRequest geminiGenerateContentRequest, model, response string) geminiGenerateContentResponse {
+	candidateCount := 1
+	if chatRequest.GenerationConfig.CandidateCount > 1 {
+		candidateCount = chatRequest.GenerationConfig.CandidateCount
+	}
+	var safetyRatings []geminiSafetyRating
+	for _, category := range geminiHarmCategories {
+		safetyRatings = append(safetyRatings, geminiSafetyRating{
+			Category:    category,
+			Probability: geminiProbabilityNegligible,
+		})
+	}
+	var candidates []geminiCandidate
+	for i := 0; i < candidateCount; i++ {
+		candidates = append(candidates, geminiCandidate{
+			Content: geminiContent{
+				Role:  geminiRoleModel,
+				Parts: []geminiPart{{Text: response}},
+			},
+			FinishReason:  geminiFinishReasonStop,
+			Index:         i,
+			SafetyRatings: safetyRatings,
+		})
+	}
+	return geminiGenerateContentResponse{
+		Candidates: candidates,
+		UsageMetadata: geminiUsageMetadata{
+			PromptTokenCount:     completionMockUsage.PromptTokens,
+			CandidatesTokenCount: completionMockUsage.CompletionTokens,
+			TotalTokenCount:      completionMockUsage.TotalTokens,
+		},
+		ModelVersion: model,
+	}
+}
+
+type geminiGenerateContentRequest struct {
+	Contents          []geminiContent        `json:"contents" validate:"required,min=1"`
+	SystemInstruction *geminiContent         `json:"systemInstruction,omitempty"`
+	GenerationConfig  geminiGenerationConfig `json:"generationConfig,omitempty"`
+	SafetySettings    []map[string]string    `json:"safetySettings,omitempty"`
+	Tools             []map[string]any       `json:"tools,omitempty"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+// StringContent returns the concatenated text of all text parts.
+func (c *geminiContent) StringContent() string {
+	var content string
+	for _, part := range c.Parts {
+		content += part.Text
+	}
+	return content
+}
+
+type geminiPart struct {
+	Text       string         `json:"text,omitempty"`
+	InlineData map[string]any `json:"inlineData,omitempty"`
+}
+
+type geminiGenerationConfig struct {
+	StopSequences    []string `json:"stopSequences,omitempty"`
+	ResponseMimeType string   `json:"responseMimeType,omitempty"`
+	CandidateCount   int      `json:"candidateCount,omitempty"`
+	MaxOutputTokens  int      `json:"maxOutputTokens,omitempty"`
+	Temperature      float64  `json:"temperature,omitempty"`
+	TopP             float64  `json:"topP,omitempty"`
+	TopK             int      `json:"topK,omitempty"`
+}
+
+type geminiGenerateContentResponse struct {
+	Candidates    []geminiCandidate   `json:"candidates"`
+	UsageMetadata geminiUsageMetadata `json:"usageMetadata"`
+	ModelVersion  string              `json:"modelVersion,omitempty"`
+}
+
+type geminiCandidate struct {
+	Content       geminiContent        `json:"content"`
+	FinishReason  string               `json:"finishReason,omitempty"`
+	Index         int                  `json:"index"`
+	SafetyRatings []geminiSafetyRating `json:"safetyRatings"`
+}
+
+type geminiSafetyRating struct {
+	Category    string `json:"category"`
+	Probability string `json:"probability"`
+}
+
+type geminiUsageMetadata struct {
+	PromptTokenCount     int `json:"promptTokenCount"`
+	CandidatesTokenCount int `json:"candidatesTokenCount"`
+	TotalTokenCount      int `json:"totalTokenCount"`
+}
@@ -0,0 +1,298 @@
+package chat
+
+import (
+	"crypto/hmac"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"llm-mock-server/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	hunyuanDomain                = "hunyuan.tencentcloudapi.com"
+	hunyuanPath                  = "/"
+	hunyuanService               = "hunyuan"
+	hunyuanActionChatCompletions = "ChatCompletions"
+	hunyuanNote                  = "以上内容为AI生成，不代表开发者立场，请勿删除或修改本标记"
+
+	tc3Algorithm     = "TC3-HMAC-SHA256"
+	tc3ScopeTerminal = "tc3_request"
+	tc3MaxClockSkew  = 5 * time.Minute
+
+	hunyuanErrorAuthFailure           = "AuthFailure.InvalidAuthorization"
+	hunyuanErrorSecretIdNotFound      = "AuthFailure.SecretIdNotFound"
+	hunyuanErrorSignatureFailure      = "AuthFailure.SignatureFailure"
+	hunyuanErrorSignatureExpire       = "AuthFailure.SignatureExpire"
+	hunyuanErrorInvalidAction         = "InvalidAction"
+	hunyuanErrorInvalidParameter      = "InvalidParameter"
+	hunyuanErrorMissingParameter      = "MissingParameter"
+	hunyuanErrorInvalidParameterValue = "InvalidParameterValue"
+)
+
+type hunyuanProvider struct {
+	secretId  string
+	secretKey string
+}
+
+func (p *hunyuanProvider) ShouldHandleRequest(ctx *gin.Context) bool {
+	context, _ := getRequestContext(ctx)
+	return context.Host == hunyuanDomain && context.Path == hunyuanPath && ctx.GetHeader("X-TC-Action") != ""
+}
+
+// registerRoutes registers the root path, which is only served to Hunyuan as Tencent Cloud APIs dispatch on
+// the X-TC-Action header.
+func (p *hunyuanProvider) registerRoutes(server *gin.Engine) {
+	server.POST(hunyuanPath, handleProviderChatCompletions(p))
+}
+
+func (p *hunyuanProvider) HandleChatCompletions(ctx *gin.Context) {
+	body, err := ctx.GetRawData()
+	if err != nil {
+		p.sendErrorResponse(ctx, hunyuanErrorInvalidParameter, err.Error())
+		return
+	}
+
+	// Validate the TC3-HMAC-SHA256 signature
+	if code, message := p.verifySignature(ctx.Request, body); code != "" {
+		p.sendErrorResponse(ctx, code, message)
+		return
+	}
+
+	if action := ctx.GetHeader("X-TC-Action"); action != hunyuanActionChatCompletions {
+		p.sendErrorResponse(ctx, hunyuanErrorInvalidAction, fmt.Sprintf("接口`%s`不存在。", action))
+		return
+	}
+
+	// Bind request body
+	var chatRequest hunyuanChatRequest
+	if err := json.Unmarshal(body, &chatRequest); err != nil {
+		p.sendErrorResponse(ctx, hunyuanErrorInvalidParameterValue, fmt.Sprintf("请求体不是合法的JSON：%v", err))
+		return
+	}
+
+	// Validate request body
+	if err := utils.Validate.Struct(chatRequest); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		for _, fieldError := range validationErrors {
+			p.sendErrorResponse(ctx, hunyuanErrorMissingParameter, fmt.Sprintf("缺少参数`%s`。", fieldError.Field()))
+			return
+		}
+	}
+
+	messages := chatRequest.Messages
+	response := prompt2Response(messages[len(messages)-1].Content)
+
+	if chatRequest.Stream {
+		p.handleStreamResponse(ctx, response)
+	} else {
+		p.handleNonStreamResponse(ctx, response)
+	}
+}
+
+// verifySignature verifies the TC3-HMAC-SHA256 Authorization header like
+// "TC3-HMAC-SHA256 Credential=AKID/2024-01-01/hunyuan/tc3_request, SignedHeaders=content-type;host, Signature=...".
+// The error code and message are returned if the verification fails.
+func (p *hunyuanProvider) verifySignature(request *http.Request, body []byte) (string, string) {
+	algorithm, params, found := strings.Cut(request.Header.Get("Authorization"), " ")
+	if !found || algorithm != tc3Algorithm {
+		return hunyuanErrorAuthFailure, "Authorization 不符合腾讯云 API 规范，请检查。"
+	}
+	var credential, signedHeaders, signature string
+	for _, param := range strings.Split(params, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		switch key {
+		case "Credential":
+			credential = value
+		case "SignedHeaders":
+			signedHeaders = value
+		case "Signature":
+			signature = value
+		}
+	}
+	credentialParts := strings.Split(credential, "/")
+	if len(credentialParts) != 4 || credentialParts[2] != hunyuanService || credentialParts[3] != tc3ScopeTerminal ||
+		signedHeaders == "" || signature == "" {
+		return hunyuanErrorAuthFailure, "Authorization 不符合腾讯云 API 规范，请检查。"
+	}
+	if credentialParts[0] != p.secretId {
+		return hunyuanErrorSecretIdNotFound, "SecretId 不存在，请输入正确的密钥。"
+	}
+
+	timestamp, err := strconv.ParseInt(request.Header.Get("X-TC-Timestamp"), 10, 64)
+	if err != nil {
+		return hunyuanErrorMissingParameter, "缺少参数`X-TC-Timestamp`。"
+	}
+	signTime := time.Unix(timestamp, 0)
+	if skew := time.Since(signTime); skew > tc3MaxClockSkew || skew < -tc3MaxClockSkew {
+		return hunyuanErrorSignatureExpire, "签名过期。Timestamp 和服务器时间相差不得超过五分钟，请检查本地时间是否和标准时间同步。"
+	}
+
+	date := signTime.UTC().Format("2006-01-02")
+	credentialScope := strings.Join([]string{date, credentialParts[2], tc3ScopeTerminal}, "/")
+	var canonicalHeaders strings.Builder
+	for _, name := range strings.Split(signedHeaders, ";") {
+		value := request.Header.Get(name)
+		if name == "host" {
+			value = request.Host
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.ToLower(strings.TrimSpace(value)) + "\n")
+	}
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		"/",
+		request.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		sha256Hex(body),
+	}, "\n")
+	stringToSign := strings.Join([]string{
+		tc3Algorithm,
+		strconv.FormatInt(timestamp, 10),
+		credentialScope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	secretDate := hmacSha256([]byte("TC3"+p.secretKey), date)
+	secretService := hmacSha256(secretDate, credentialParts[2])
+	secretSigning := hmacSha256(secretService, tc3ScopeTerminal)
+	expectedSignature := hex.EncodeToString(hmacSha256(secretSigning, stringToSign))
+	if !hmac.Equal([]byte(expectedSignature), []byte(signature)) {
+		return hunyuanErrorSignatureFailure, "请求签名验证失败，请检查您的签名计算是否正确。"
+	}
+	return "", ""
+}
+
+// sendErrorResponse sends an error response, which is returned with HTTP status 200 by Tencent Cloud.
+func (p *hunyuanProvider) sendErrorResponse(ctx *gin.Context, code, message string) {
+	ctx.JSON(http.StatusOK, hunyuanResponse{
+		Response: hunyuanChatResponse{
+			Error: &hunyuanError{
+				Code:    code,
+				Message: message,
+			},
+			RequestId: completionMockId,
+		},
+	})
+}
+
+func (p *hunyuanProvider) handleStreamResponse(ctx *gin.Context, response string) {
+	utils.SetEventStreamHeaders(ctx)
+	dataChan := make(chan string)
+	stopChan := make(chan bool, 1)
+
+	go func() {
+		send := func(content, finishReason string) {
+			// Stream chunks are not wrapped in the Response envelope
+			streamResponse := p.createChatResponse()
+			streamResponse.Choices = []hunyuanChoice{
+				{
+					Delta:        &hunyuanMessage{Role: roleAssistant, Content: content},
+					FinishReason: finishReason,
+				},
+			}
+			jsonStr, _ := json.Marshal(streamResponse)
+			dataChan <- string(jsonStr)
+		}
+
+		for _, s := range response {
+			send(string(s), "")
+
+			// Simulate response delay
+			time.Sleep(200 * time.Millisecond)
+		}
+
+		// The last chunk carries the finish reason even if the response is empty
+		send("", stopReason)
+		stopChan <- true
+	}()
+
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case data := <-dataChan:
+			ctx.Render(-1, streamEvent{Data: "data: " + data})
+			return true
+		case <-stopChan:
+			return false
+		}
+	})
+}
+
+func (p *hunyuanProvider) handleNonStreamResponse(ctx *gin.Context, response string) {
+	chatResponse := p.createChatResponse()
+	chatResponse.Choices = []hunyuanChoice{
+		{
+			Message:      &hunyuanMessage{Role: roleAssistant, Content: response},
+			FinishReason: stopReason,
+		},
+	}
+	ctx.JSON(http.StatusOK, hunyuanResponse{Response: chatResponse})
+}
+
+func (p *hunyuanProvider) createChatResponse() hunyuanChatResponse {
+	return hunyuanChatResponse{
+		RequestId: completionMockId,
+		Note:      hunyuanNote,
+		Created:   completionMockCreated,
+		Id:        completionMockId,
+		Usage: &hunyuanUsage{
+			PromptTokens:     completionMockUsage.PromptTokens,
+			CompletionTokens: completionMockUsage.CompletionTokens,
+			TotalTokens:      completionMockUsage.TotalTokens,
+		},
+	}
+}
+
+type hunyuanChatRequest struct {
+	Model             string           `json:"Model" validate:"required"`
+	Messages          []hunyuanMessage `json:"Messages" validate:"required,min=1"`
+	Stream            bool             `json:"Stream,omitempty"`
+	StreamModeration  bool             `json:"StreamModeration,omitempty"`
+	TopP              float64          `json:"TopP,omitempty"`
+	Temperature       float64          `json:"Temperature,omitempty"`
+	EnableEnhancement *bool            `json:"EnableEnhancement,omitempty"`
+}
+
+type hunyuanMessage struct {
+	Role    string `json:"Role"`
+	Content string `json:"Content"`
+}
+
+type hunyuanResponse struct {
+	Response hunyuanChatResponse `json:"Response"`
+}
+
+type hunyuanChatResponse struct {
+	RequestId string          `json:"RequestId"`
+	Note      string          `json:"Note,omitempty"`
+	Choices   []hunyuanChoice `json:"Choices,omitempty"`
+	Created   int64           `json:"Created,omitempty"`
+	Id        string          `json:"Id,omitempty"`
+	Usage     *hunyuanUsage   `json:"Usage,omitempty"`
+	Error     *hunyuanError   `json:"Error,omitempty"`
+}
+
+type hunyuanChoice struct {
+	Message      *hunyuanMessage `json:"Message,omitempty"`
+	Delta        *hunyuanMessage `json:"Delta,omitempty"`
+	FinishReason string          `json:"FinishReason"`
+}
+
+type hunyuanUsage struct {
+	PromptTokens     int `json:"PromptTokens"`
+	CompletionTokens int `json:"CompletionTokens"`
+	TotalTokens      int `json:"TotalTokens"`
+}
+
+type hunyuanError struct {
+	Code    string `json:"Code"`
+	Message string `json:"Message"`
+}
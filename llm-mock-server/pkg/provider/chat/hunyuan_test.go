@@ -0,0 +1,111 @@
+package chat
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testHunyuanSecretId  = "AKIDllm-mock"
+	testHunyuanSecretKey = "llm-mock-secret"
+)
+
+// signTc3 signs the request at the given time the way the Tencent Cloud SDKs do.
+func signTc3(request *http.Request, body []byte, secretKey string, signTime time.Time) {
+	timestamp := strconv.FormatInt(signTime.Unix(), 10)
+	date := signTime.UTC().Format("2006-01-02")
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-TC-Action", hunyuanActionChatCompletions)
+	request.Header.Set("X-TC-Timestamp", timestamp)
+
+	canonicalRequest := strings.Join([]string{
+		http.MethodPost,
+		"/",
+		"",
+		"content-type:application/json\nhost:" + hunyuanDomain + "\n",
+		"content-type;host",
+		sha256Hex(body),
+	}, "\n")
+	credentialScope := date + "/" + hunyuanService + "/" + tc3ScopeTerminal
+	stringToSign := strings.Join([]string{
+		tc3Algorithm,
+		timestamp,
+		credentialScope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+	secretDate := hmacSha256([]byte("TC3"+secretKey), date)
+	secretService := hmacSha256(secretDate, hunyuanService)
+	secretSigning := hmacSha256(secretService, tc3ScopeTerminal)
+	signature := hex.EncodeToString(hmacSha256(secretSigning, stringToSign))
+	request.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=content-type;host, Signature=%s",
+		tc3Algorithm, testHunyuanSecretId, credentialScope, signature))
+}
+
+func TestHunyuanVerifySignature(t *testing.T) {
+	p := &hunyuanProvider{secretId: testHunyuanSecretId, secretKey: testHunyuanSecretKey}
+	body := []byte(`{"Model":"hunyuan-lite","Messages":[{"Role":"user","Content":"Hello"}]}`)
+
+	tests := []struct {
+		name     string
+		sign     func(request *http.Request)
+		body     []byte
+		wantCode string
+	}{
+		{
+			name: "valid signature",
+			sign: func(request *http.Request) { signTc3(request, body, testHunyuanSecretKey, time.Now()) },
+			body: body,
+		},
+		{
+			name:     "tampered body",
+			sign:     func(request *http.Request) { signTc3(request, body, testHunyuanSecretKey, time.Now()) },
+			body:     []byte(`{"Model":"hunyuan-pro","Messages":[{"Role":"user","Content":"Hello"}]}`),
+			wantCode: hunyuanErrorSignatureFailure,
+		},
+		{
+			name: "tampered signature",
+			sign: func(request *http.Request) {
+				signTc3(request, body, testHunyuanSecretKey, time.Now())
+				request.Header.Set("Authorization", tamperLastChar(request.Header.Get("Authorization")))
+			},
+			body:     body,
+			wantCode: hunyuanErrorSignatureFailure,
+		},
+		{
+			name:     "wrong secret key",
+			sign:     func(request *http.Request) { signTc3(request, body, "wrong-secret", time.Now()) },
+			body:     body,
+			wantCode: hunyuanErrorSignatureFailure,
+		},
+		{
+			name:     "expired timestamp",
+			sign:     func(request *http.Request) { signTc3(request, body, testHunyuanSecretKey, time.Now().Add(-time.Hour)) },
+			body:     body,
+			wantCode: hunyuanErrorSignatureExpire,
+		},
+		{
+			name:     "missing authorization",
+			sign:     func(request *http.Request) {},
+			body:     body,
+			wantCode: hunyuanErrorAuthFailure,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "https://"+hunyuanDomain+"/", bytes.NewReader(tt.body))
+			tt.sign(request)
+
+			if code, message := p.verifySignature(request, tt.body); code != tt.wantCode {
+				t.Errorf("code = %q (%s), want %q", code, message, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package chat
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// jwtToken is a decoded but not yet verified JSON Web Token.
+type jwtToken struct {
+	Header       map[string]any
+	Claims       map[string]any
+	SigningInput string
+	Signature    []byte
+}
+
+// parseJwt decodes a compact serialized JWT without verifying its signature.
+func parseJwt(token string) (*jwtToken, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("token contains an invalid number of segments")
+	}
+	header := map[string]any{}
+	if err := decodeJwtSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("invalid token header: %v", err)
+	}
+	claims := map[string]any{}
+	if err := decodeJwtSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("invalid token claims: %v", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid token signature: %v", err)
+	}
+	return &jwtToken{
+		Header:       header,
+		Claims:       claims,
+		SigningInput: parts[0] + "." + parts[1],
+		Signature:    signature,
+	}, nil
+}
+
+func decodeJwtSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// numericClaim returns the claim as an int64, as JSON numbers are decoded as float64.
+func (t *jwtToken) numericClaim(name string) (int64, bool) {
+	value, ok := t.Claims[name].(float64)
+	return int64(value), ok
+}
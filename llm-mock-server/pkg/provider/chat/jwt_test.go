@@ -0,0 +1,67 @@
+package chat
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// signJwt serializes the header and the claims and appends the signature computed by sign over the signing input.
+func signJwt(t *testing.T, header, claims map[string]any, sign func(signingInput []byte) []byte) string {
+	t.Helper()
+	encode := func(v any) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("failed to marshal JWT segment: %v", err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	signingInput := encode(header) + "." + encode(claims)
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sign([]byte(signingInput)))
+}
+
+// tamperJwtSignature replaces the first character of the signature, as the last one may only hold padding bits.
+func tamperJwtSignature(token string) string {
+	i := strings.LastIndex(token, ".") + 1
+	replacement := "A"
+	if token[i:i+1] == replacement {
+		replacement = "B"
+	}
+	return token[:i] + replacement + token[i+1:]
+}
+
+func TestParseJwt(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{name: "valid token", token: "eyJhbGciOiJIUzI1NiJ9.eyJleHAiOjF9.c2lnbmF0dXJl"},
+		{name: "missing segment", token: "eyJhbGciOiJIUzI1NiJ9.eyJleHAiOjF9", wantErr: true},
+		{name: "invalid header", token: "not-json.eyJleHAiOjF9.c2lnbmF0dXJl", wantErr: true},
+		{name: "invalid claims", token: "eyJhbGciOiJIUzI1NiJ9.not-json.c2lnbmF0dXJl", wantErr: true},
+		{name: "invalid signature", token: "eyJhbGciOiJIUzI1NiJ9.eyJleHAiOjF9.not+base64url", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jwt, err := parseJwt(tt.token)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if jwt.Header["alg"] != "HS256" || string(jwt.Signature) != "signature" {
+				t.Errorf("unexpected token: %+v", jwt)
+			}
+			if exp, ok := jwt.numericClaim("exp"); !ok || exp != 1 {
+				t.Errorf("exp = %d, %v, want 1, true", exp, ok)
+			}
+		})
+	}
+}
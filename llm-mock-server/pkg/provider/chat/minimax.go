@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"llm-mock-server/pkg/utils"
@@ -17,13 +18,35 @@ const (
 	minimaxDomain = "api.minimax.chat"
 	// minimaxChatCompletionProPath represents the API path for chat completion Pro API which has a different response format from OpenAI's.
 	minimaxChatCompletionProPath = "/v1/text/chatcompletion_pro"
+	// minimaxChatCompletionV2Path represents the API path for chat completion v2 API which is similar to OpenAI's,
+	// but reports errors and content moderation results in the body of HTTP 200 responses.
+	minimaxChatCompletionV2Path = "/v1/text/chatcompletion_v2"
+
+	minimaxStatusSuccess             = 0
+	minimaxStatusLoginFail           = 1004
+	minimaxStatusInsufficientBalance = 1008
+	minimaxStatusInputSensitive      = 1026
+	minimaxStatusOutputSensitive     = 1027
+	minimaxStatusInvalidParams       = 2013
+
+	// minimaxInsufficientBalanceApiKey is the API key of the mock account that has run out of balance.
+	minimaxInsufficientBalanceApiKey = "mock-insufficient-balance"
+	// minimaxMockInputSensitiveWord and minimaxMockOutputSensitiveWord are the words that make the prompt and the
+	// response flagged by the content moderation respectively.
+	minimaxMockInputSensitiveWord  = "mock-input-sensitive"
+	minimaxMockOutputSensitiveWord = "mock-output-sensitive"
+	// minimaxSensitiveTypeSevere is the sensitive type reported for flagged content.
+	minimaxSensitiveTypeSevere = 1
+
+	minimaxDefaultAssistantName = "MM智能助理"
 )
 
 type minimaxProvider struct{}
 
 func (p *minimaxProvider) ShouldHandleRequest(ctx *gin.Context) bool {
 	context, _ := getRequestContext(ctx)
-	if context.Host == minimaxDomain && context.Path == minimaxChatCompletionProPath {
+	if context.Host == minimaxDomain &&
+		(context.Path == minimaxChatCompletionProPath || context.Path == minimaxChatCompletionV2Path) {
 		return true
 	}
 	return false
@@ -33,15 +56,28 @@ func (p *minimaxProvider) HandleChatCompletions(ctx *gin.Context) {
 	// Validate Authorization header
 	authHeader := ctx.GetHeader("Authorization")
 	if authHeader == "" {
-		p.sendErrorResponse(ctx, 1004,
+		p.sendErrorResponse(ctx, minimaxStatusLoginFail,
 			"login fail: Please carry the API secret key in the 'Authorization' field of the request header")
 		return
 	}
+	if strings.TrimPrefix(authHeader, "Bearer ") == minimaxInsufficientBalanceApiKey {
+		p.sendErrorResponse(ctx, minimaxStatusInsufficientBalance, "insufficient balance")
+		return
+	}
+
+	context, _ := getRequestContext(ctx)
+	if context.Path == minimaxChatCompletionV2Path {
+		p.handleChatCompletionV2(ctx)
+	} else {
+		p.handleChatCompletionPro(ctx)
+	}
+}
 
+func (p *minimaxProvider) handleChatCompletionPro(ctx *gin.Context) {
 	// Bind request body
 	var chatRequest minimaxChatCompletionProRequest
 	if err := ctx.ShouldBindJSON(&chatRequest); err != nil {
-		p.sendErrorResponse(ctx, 2013,
+		p.sendErrorResponse(ctx, minimaxStatusInvalidParams,
 			fmt.Sprintf("invalid params: %v", err.Error()))
 		return
 	}
@@ -50,7 +86,7 @@ func (p *minimaxProvider) HandleChatCompletions(ctx *gin.Context) {
 	if err := utils.Validate.Struct(chatRequest); err != nil {
 		validationErrors := err.(validator.ValidationErrors)
 		for _, fieldError := range validationErrors {
-			p.sendErrorResponse(ctx, 2013,
+			p.sendErrorResponse(ctx, minimaxStatusInvalidParams,
 				fmt.Sprintf("invalid params: %v", fieldError.Error()))
 			return
 		}
@@ -150,12 +186,161 @@ func (p *minimaxProvider) createProResp(model, senderType, senderName, reply str
 		Usage: completionMockUsage,
 		Id:    completionMockId,
 		BaseResp: minimaxBaseResp{
-			StatusCode: 0,
+			StatusCode: minimaxStatusSuccess,
 			StatusMsg:  "",
 		},
 	}
 }
 
+func (p *minimaxProvider) handleChatCompletionV2(ctx *gin.Context) {
+	// Bind request body
+	var chatRequest minimaxChatCompletionV2Request
+	if err := ctx.ShouldBindJSON(&chatRequest); err != nil {
+		p.sendErrorResponse(ctx, minimaxStatusInvalidParams,
+			fmt.Sprintf("invalid params: %v", err.Error()))
+		return
+	}
+
+	// Validate request body
+	if err := utils.Validate.Struct(chatRequest); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		for _, fieldError := range validationErrors {
+			p.sendErrorResponse(ctx, minimaxStatusInvalidParams,
+				fmt.Sprintf("invalid params: %v", fieldError.Error()))
+			return
+		}
+	}
+
+	prompt := ""
+	if chatRequest.Messages[len(chatRequest.Messages)-1].IsStringContent() {
+		prompt = chatRequest.Messages[len(chatRequest.Messages)-1].StringContent()
+	}
+
+	// Flagged prompts are rejected before generation
+	if strings.Contains(prompt, minimaxMockInputSensitiveWord) {
+		completion := p.createV2Resp(chatRequest.Model, objectChatCompletion)
+		completion.InputSensitive = true
+		completion.InputSensitiveType = minimaxSensitiveTypeSevere
+		completion.BaseResp = &minimaxBaseResp{StatusCode: minimaxStatusInputSensitive, StatusMsg: "input new_sensitive"}
+		ctx.JSON(http.StatusOK, completion)
+		return
+	}
+
+	response := prompt2Response(prompt)
+	if chatRequest.Stream {
+		p.handleV2StreamResponse(ctx, chatRequest, response)
+	} else {
+		p.handleV2NonStreamResponse(ctx, chatRequest, response)
+	}
+}
+
+func (p *minimaxProvider) handleV2StreamResponse(ctx *gin.Context, chatRequest minimaxChatCompletionV2Request, response string) {
+	utils.SetEventStreamHeaders(ctx)
+	dataChan := make(chan string)
+	stopChan := make(chan bool, 1)
+
+	// The stream is cut off where the flagged content begins
+	responseRunes := []rune(response)
+	sensitiveIndex := strings.Index(response, minimaxMockOutputSensitiveWord)
+	if sensitiveIndex >= 0 {
+		responseRunes = []rune(response[:sensitiveIndex])
+	}
+
+	go func() {
+		for _, s := range responseRunes {
+			// Chunks carry the delta without finish_reason, and empty usage
+			streamResponse := p.createV2Resp(chatRequest.Model, objectChatCompletionChunk)
+			streamResponse.Choices = []minimaxV2Choice{
+				{Delta: p.createV2Message(chatRequest, string(s))},
+			}
+			streamResponse.Usage = &minimaxV2Usage{}
+			jsonStr, _ := json.Marshal(streamResponse)
+			dataChan <- string(jsonStr)
+
+			// Simulate response delay
+			time.Sleep(200 * time.Millisecond)
+		}
+		stopChan <- true
+	}()
+
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case data := <-dataChan:
+			ctx.Render(-1, streamEvent{Data: "data: " + data})
+			return true
+		case <-stopChan:
+			// Instead of [DONE], the stream ends with the complete message, the usage and the base_resp
+			var completion minimaxChatCompletionV2Resp
+			if sensitiveIndex >= 0 {
+				completion = p.createOutputSensitiveV2Resp(chatRequest.Model)
+			} else {
+				completion = p.createV2CompletionResp(chatRequest, response)
+			}
+			jsonStr, _ := json.Marshal(completion)
+			ctx.Render(-1, streamEvent{Data: fmt.Sprintf("data: %s", jsonStr)})
+			return false
+		}
+	})
+}
+
+func (p *minimaxProvider) handleV2NonStreamResponse(ctx *gin.Context, chatRequest minimaxChatCompletionV2Request, response string) {
+	if strings.Contains(response, minimaxMockOutputSensitiveWord) {
+		ctx.JSON(http.StatusOK, p.createOutputSensitiveV2Resp(chatRequest.Model))
+		return
+	}
+	ctx.JSON(http.StatusOK, p.createV2CompletionResp(chatRequest, response))
+}
+
+// createV2Resp returns the v2 response without choices, which always carries the content moderation results.
+func (p *minimaxProvider) createV2Resp(model, object string) minimaxChatCompletionV2Resp {
+	return minimaxChatCompletionV2Resp{
+		Id:      completionMockId,
+		Created: completionMockCreated,
+		Model:   model,
+		Object:  object,
+	}
+}
+
+func (p *minimaxProvider) createV2CompletionResp(chatRequest minimaxChatCompletionV2Request, response string) minimaxChatCompletionV2Resp {
+	completion := p.createV2Resp(chatRequest.Model, objectChatCompletion)
+	completion.Choices = []minimaxV2Choice{
+		{
+			FinishReason: stopReason,
+			Message:      p.createV2Message(chatRequest, response),
+		},
+	}
+	completion.Usage = &minimaxV2Usage{
+		TotalTokens:      completionMockUsage.TotalTokens,
+		PromptTokens:     completionMockUsage.PromptTokens,
+		CompletionTokens: completionMockUsage.CompletionTokens,
+	}
+	completion.BaseResp = &minimaxBaseResp{StatusCode: minimaxStatusSuccess}
+	return completion
+}
+
+func (p *minimaxProvider) createOutputSensitiveV2Resp(model string) minimaxChatCompletionV2Resp {
+	completion := p.createV2Resp(model, objectChatCompletion)
+	completion.OutputSensitive = true
+	completion.OutputSensitiveType = minimaxSensitiveTypeSevere
+	completion.BaseResp = &minimaxBaseResp{StatusCode: minimaxStatusOutputSensitive, StatusMsg: "output new_sensitive"}
+	return completion
+}
+
+// createV2Message returns the assistant message, named after the assistant in the request if any.
+func (p *minimaxProvider) createV2Message(chatRequest minimaxChatCompletionV2Request, content string) *minimaxV2Message {
+	name := minimaxDefaultAssistantName
+	for _, message := range chatRequest.Messages {
+		if message.Role == roleAssistant && message.Name != "" {
+			name = message.Name
+		}
+	}
+	return &minimaxV2Message{
+		Role:    roleAssistant,
+		Name:    name,
+		Content: content,
+	}
+}
+
 // minimaxChatCompletionProRequest represents the structure of a chat completion Pro request.
 type minimaxChatCompletionProRequest struct {
 	Model             string                  `json:"model" validate:"required"`
@@ -213,3 +398,57 @@ type minimaxChoice struct {
 	Index        int64            `json:"index"`
 	FinishReason string           `json:"finish_reason"`
 }
+
+// minimaxChatCompletionV2Request represents the structure of a chat completion v2 request.
+type minimaxChatCompletionV2Request struct {
+	Model             string        `json:"model" validate:"required"`
+	Messages          []chatMessage `json:"messages" validate:"required,min=1"`
+	Stream            bool          `json:"stream,omitempty"`
+	MaxTokens         int64         `json:"max_tokens,omitempty"`
+	Temperature       float64       `json:"temperature,omitempty" validate:"omitempty,gt=0,lte=1"`
+	TopP              float64       `json:"top_p,omitempty" validate:"omitempty,gt=0,lte=1"`
+	MaskSensitiveInfo bool          `json:"mask_sensitive_info,omitempty"`
+	Tools             []tool        `json:"tools,omitempty"`
+	ToolChoice        any           `json:"tool_choice,omitempty"`
+}
+
+// minimaxChatCompletionV2Resp represents the structure of a chat completion v2 response or stream chunk.
+// The content moderation results are reported in every response, while base_resp is omitted in all but the last chunk.
+type minimaxChatCompletionV2Resp struct {
+	Id                  string            `json:"id"`
+	Choices             []minimaxV2Choice `json:"choices"`
+	Created             int64             `json:"created"`
+	Model               string            `json:"model"`
+	Object              string            `json:"object"`
+	Usage               *minimaxV2Usage   `json:"usage"`
+	InputSensitive      bool              `json:"input_sensitive"`
+	InputSensitiveType  int               `json:"input_sensitive_type"`
+	OutputSensitive     bool              `json:"output_sensitive"`
+	OutputSensitiveType int               `json:"output_sensitive_type"`
+	BaseResp            *minimaxBaseResp  `json:"base_resp,omitempty"`
+}
+
+// minimaxV2Choice represents a result option. Stream chunks carry the delta, while the last chunk carries the complete
+// message like the non-stream response.
+type minimaxV2Choice struct {
+	FinishReason string            `json:"finish_reason,omitempty"`
+	Index        int               `json:"index"`
+	Message      *minimaxV2Message `json:"message,omitempty"`
+	Delta        *minimaxV2Message `json:"delta,omitempty"`
+}
+
+// minimaxV2Message represents a message generated by the model.
+type minimaxV2Message struct {
+	Content      string `json:"content"`
+	Role         string `json:"role"`
+	Name         string `json:"name,omitempty"`
+	AudioContent string `json:"audio_content"`
+}
+
+// minimaxV2Usage represents the token usage, which is all zero in stream chunks but the last one.
+type minimaxV2Usage struct {
+	TotalTokens      int `json:"total_tokens"`
+	TotalCharacters  int `json:"total_characters"`
+	PromptTokens     int `json:"prompt_tokens,omitempty"`
+	CompletionTokens int `json:"completion_tokens,omitempty"`
+}
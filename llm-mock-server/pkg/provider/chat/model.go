@@ -1,5 +1,7 @@
 package chat
 
+import "llm-mock-server/pkg/provider"
+
 const (
 	completionMockId = "chatcmpl-llm-mock"
 
@@ -7,6 +9,7 @@ const (
 	objectChatCompletionChunk = "chat.completion.chunk"
 
 	roleAssistant = "assistant"
+	roleSystem    = "system"
 
 	stopReason = "stop"
 
@@ -24,9 +27,14 @@ var (
 )
 
 type chatCompletionRequest struct {
-	Model            string                 `json:"model" validate:"required"`
-	Messages         []chatMessage          `json:"messages" validate:"required,min=1"`
-	MaxTokens        int                    `json:"max_tokens,omitempty"`
+	Model     string        `json:"model" validate:"required"`
+	Messages  []chatMessage `json:"messages" validate:"required,min=1"`
+	MaxTokens int           `json:"max_tokens,omitempty"`
+	// ReasoningEffort is supported by OpenAI reasoning models, while EnableThinking and ThinkingBudget
+	// are supported by Qwen thinking models.
+	ReasoningEffort  string                 `json:"reasoning_effort,omitempty"`
+	EnableThinking   *bool                  `json:"enable_thinking,omitempty"`
+	ThinkingBudget   int                    `json:"thinking_budget,omitempty"`
 	FrequencyPenalty float64                `json:"frequency_penalty,omitempty"`
 	N                int                    `json:"n,omitempty"`
 	PresencePenalty  float64                `json:"presence_penalty,omitempty"`
@@ -70,6 +78,8 @@ type chatCompletionResponse struct {
 	SystemFingerprint string                 `json:"system_fingerprint,omitempty"`
 	Object            string                 `json:"object,omitempty"`
 	Usage             *usage                 `json:"usage"`
+	// PromptFilterResults is only returned by Azure OpenAI
+	PromptFilterResults []provider.AzurePromptFilterResult `json:"prompt_filter_results,omitempty"`
 }
 
 type chatCompletionChoice struct {
@@ -78,19 +88,27 @@ type chatCompletionChoice struct {
 	Delta        *chatMessage           `json:"delta,omitempty"`
 	FinishReason *string                `json:"finish_reason"`
 	Logprobs     map[string]interface{} `json:"logprobs"`
+	// ContentFilterResults is only returned by Azure OpenAI
+	ContentFilterResults provider.AzureContentFilterResults `json:"content_filter_results,omitempty"`
 }
 
 type usage struct {
-	PromptTokens     int `json:"prompt_tokens,omitempty"`
-	CompletionTokens int `json:"completion_tokens,omitempty"`
-	TotalTokens      int `json:"total_tokens,omitempty"`
+	PromptTokens            int                      `json:"prompt_tokens,omitempty"`
+	CompletionTokens        int                      `json:"completion_tokens,omitempty"`
+	TotalTokens             int                      `json:"total_tokens,omitempty"`
+	CompletionTokensDetails *completionTokensDetails `json:"completion_tokens_details,omitempty"`
+}
+
+type completionTokensDetails struct {
+	ReasoningTokens int `json:"reasoning_tokens"`
 }
 
 type chatMessage struct {
-	Name      string     `json:"name,omitempty"`
-	Role      string     `json:"role,omitempty"`
-	Content   any        `json:"content,omitempty"`
-	ToolCalls []toolCall `json:"tool_calls,omitempty"`
+	Name             string     `json:"name,omitempty"`
+	Role             string     `json:"role,omitempty"`
+	Content          any        `json:"content,omitempty"`
+	ReasoningContent string     `json:"reasoning_content,omitempty"`
+	ToolCalls        []toolCall `json:"tool_calls,omitempty"`
 }
 
 type messageContent struct {
@@ -0,0 +1,442 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"llm-mock-server/pkg/provider"
+	"llm-mock-server/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	moonshotDomain             = "api.moonshot.cn"
+	moonshotChatCompletionPath = "/v1/chat/completions"
+	moonshotFilesPath          = "/v1/files"
+	moonshotFileIdPrefix       = "cn"
+
+	moonshotPurposeFileExtract = "file-extract"
+	moonshotObjectFile         = "file"
+	moonshotObjectList         = "list"
+	moonshotFileStatusOk       = "ok"
+	// moonshotMaxFileSize and moonshotMaxFileCount are the limits of the uploaded files of an account.
+	moonshotMaxFileSize  = 100 << 20
+	moonshotMaxFileCount = 1000
+
+	moonshotErrorTypeInvalidRequest = "invalid_request_error"
+	moonshotErrorTypeAuthentication = "invalid_authentication_error"
+	moonshotErrorTypeNotFound       = "resource_not_found_error"
+	moonshotErrorTypeExceeded       = "exceeded_current_quota_error"
+)
+
+type moonshotProvider struct {
+	files *moonshotFileStore
+}
+
+func (p *moonshotProvider) ShouldHandleRequest(ctx *gin.Context) bool {
+	context, _ := getRequestContext(ctx)
+	return context.Host == moonshotDomain && context.Path == moonshotChatCompletionPath
+}
+
+// registerRoutes registers the file endpoints, which are not JSON requests like chat completions. They are only
+// served to the Moonshot host as their paths are the same as those of the OpenAI file endpoints.
+func (p *moonshotProvider) registerRoutes(server *gin.Engine) {
+	files := server.Group(moonshotFilesPath, p.requireMoonshotHost)
+	files.POST("", p.handleUploadFile)
+	files.GET("", p.handleListFiles)
+	files.GET("/:id", p.handleRetrieveFile)
+	files.DELETE("/:id", p.handleDeleteFile)
+	files.GET("/:id/content", p.handleRetrieveFileContent)
+}
+
+// requireMoonshotHost aborts the requests to other hosts with 404.
+func (p *moonshotProvider) requireMoonshotHost(ctx *gin.Context) {
+	if ctx.Request.Host != moonshotDomain {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Not found"})
+	}
+}
+
+// validateAuthorization checks the Authorization header. An error response is sent and false is returned if it is missing.
+func (p *moonshotProvider) validateAuthorization(ctx *gin.Context) bool {
+	if ctx.GetHeader("Authorization") == "" {
+		p.sendErrorResponse(ctx, http.StatusUnauthorized, moonshotErrorTypeAuthentication, "Invalid Authentication")
+		return false
+	}
+	return true
+}
+
+func (p *moonshotProvider) sendErrorResponse(ctx *gin.Context, statusCode int, errorType, errorMsg string) {
+	ctx.JSON(statusCode, moonshotErrorResp{
+		Error: moonshotError{
+			Type:    errorType,
+			Message: errorMsg,
+		},
+	})
+}
+
+func (p *moonshotProvider) handleUploadFile(ctx *gin.Context) {
+	if !p.validateAuthorization(ctx) {
+		return
+	}
+
+	purpose := ctx.PostForm("purpose")
+	if purpose != moonshotPurposeFileExtract {
+		p.sendErrorResponse(ctx, http.StatusBadRequest, moonshotErrorTypeInvalidRequest,
+			fmt.Sprintf("invalid purpose: %s, only %s is supported", purpose, moonshotPurposeFileExtract))
+		return
+	}
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		p.sendErrorResponse(ctx, http.StatusBadRequest, moonshotErrorTypeInvalidRequest,
+			fmt.Sprintf("file is required: %v", err))
+		return
+	}
+	if fileHeader.Size > moonshotMaxFileSize {
+		p.sendErrorResponse(ctx, http.StatusBadRequest, moonshotErrorTypeInvalidRequest,
+			fmt.Sprintf("file size is too large, max file size is %d bytes", moonshotMaxFileSize))
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		p.sendErrorResponse(ctx, http.StatusBadRequest, moonshotErrorTypeInvalidRequest, err.Error())
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		p.sendErrorResponse(ctx, http.StatusBadRequest, moonshotErrorTypeInvalidRequest, err.Error())
+		return
+	}
+
+	stored, ok := p.files.add(fileHeader.Filename, purpose, fileHeader.Header.Get("Content-Type"), data)
+	if !ok {
+		p.sendErrorResponse(ctx, http.StatusBadRequest, moonshotErrorTypeExceeded,
+			fmt.Sprintf("too many files, max file count is %d, please delete some files", moonshotMaxFileCount))
+		return
+	}
+	ctx.JSON(http.StatusOK, stored.file)
+}
+
+func (p *moonshotProvider) handleListFiles(ctx *gin.Context) {
+	if !p.validateAuthorization(ctx) {
+		return
+	}
+	ctx.JSON(http.StatusOK, moonshotFileList{
+		Object: moonshotObjectList,
+		Data:   p.files.list(),
+	})
+}
+
+func (p *moonshotProvider) handleRetrieveFile(ctx *gin.Context) {
+	if !p.validateAuthorization(ctx) {
+		return
+	}
+	stored, found := p.files.get(ctx.Param("id"))
+	if !found {
+		p.sendFileNotFoundResponse(ctx)
+		return
+	}
+	ctx.JSON(http.StatusOK, stored.file)
+}
+
+func (p *moonshotProvider) handleDeleteFile(ctx *gin.Context) {
+	if !p.validateAuthorization(ctx) {
+		return
+	}
+	if !p.files.delete(ctx.Param("id")) {
+		p.sendFileNotFoundResponse(ctx)
+		return
+	}
+	ctx.JSON(http.StatusOK, moonshotFileDeleted{
+		Id:      ctx.Param("id"),
+		Object:  moonshotObjectFile,
+		Deleted: true,
+	})
+}
+
+// handleRetrieveFileContent returns the text extracted from the file, which is meant to be sent as a system message.
+func (p *moonshotProvider) handleRetrieveFileContent(ctx *gin.Context) {
+	if !p.validateAuthorization(ctx) {
+		return
+	}
+	stored, found := p.files.get(ctx.Param("id"))
+	if !found {
+		p.sendFileNotFoundResponse(ctx)
+		return
+	}
+	ctx.JSON(http.StatusOK, moonshotFileContent{
+		Content:  stored.content,
+		FileType: stored.fileType,
+		Filename: stored.file.Filename,
+		Title:    "",
+		Type:     moonshotObjectFile,
+	})
+}
+
+func (p *moonshotProvider) sendFileNotFoundResponse(ctx *gin.Context) {
+	p.sendErrorResponse(ctx, http.StatusNotFound, moonshotErrorTypeNotFound,
+		fmt.Sprintf("file not found: %s", ctx.Param("id")))
+}
+
+func (p *moonshotProvider) HandleChatCompletions(ctx *gin.Context) {
+	if !p.validateAuthorization(ctx) {
+		return
+	}
+
+	// Bind request body
+	var chatRequest chatCompletionRequest
+	if err := ctx.ShouldBindJSON(&chatRequest); err != nil {
+		p.sendErrorResponse(ctx, http.StatusBadRequest, moonshotErrorTypeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate request body
+	if err := utils.Validate.Struct(chatRequest); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		for _, fieldError := range validationErrors {
+			p.sendErrorResponse(ctx, http.StatusBadRequest, moonshotErrorTypeInvalidRequest, fieldError.Error())
+			return
+		}
+	}
+
+	prompt := ""
+	if chatRequest.Messages[len(chatRequest.Messages)-1].IsStringContent() {
+		prompt = chatRequest.Messages[len(chatRequest.Messages)-1].StringContent()
+	}
+	response := prompt2Response(prompt)
+	chatUsage := p.createUsage(chatRequest)
+
+	if chatRequest.Stream {
+		p.handleStreamResponse(ctx, chatRequest, response, chatUsage)
+	} else {
+		completion := createChatCompletionResponse(chatRequest.Model, response)
+		completion.Usage = &chatUsage
+		ctx.JSON(http.StatusOK, completion)
+	}
+}
+
+// createUsage returns the usage in which the prompt tokens include the content of the files referenced by the
+// system messages.
+func (p *moonshotProvider) createUsage(chatRequest chatCompletionRequest) usage {
+	fileUsage := completionMockUsage
+	for _, message := range chatRequest.Messages {
+		if message.Role != roleSystem || !message.IsStringContent() {
+			continue
+		}
+		if p.files.containsContent(message.StringContent()) {
+			tokens := moonshotEstimateTokens(message.StringContent())
+			fileUsage.PromptTokens += tokens
+			fileUsage.TotalTokens += tokens
+		}
+	}
+	return fileUsage
+}
+
+// handleStreamResponse streams the response like OpenAI, except that the usage is sent in the choice of the last chunk.
+func (p *moonshotProvider) handleStreamResponse(ctx *gin.Context, chatRequest chatCompletionRequest, response string, chatUsage usage) {
+	utils.SetEventStreamHeaders(ctx)
+	dataChan := make(chan string)
+	stopChan := make(chan bool, 1)
+	streamResponse := moonshotChatCompletionChunk{
+		Id:      completionMockId,
+		Object:  objectChatCompletionChunk,
+		Created: completionMockCreated,
+		Model:   chatRequest.Model,
+	}
+	go func() {
+		send := func(choice moonshotChunkChoice) {
+			streamResponse.Choices = []moonshotChunkChoice{choice}
+			jsonStr, _ := json.Marshal(streamResponse)
+			dataChan <- string(jsonStr)
+		}
+
+		for _, s := range response {
+			send(moonshotChunkChoice{Delta: &chatMessage{Content: string(s)}})
+
+			// Simulate response delay
+			time.Sleep(200 * time.Millisecond)
+		}
+
+		// The last chunk carries the finish reason and the usage even if the response is empty
+		send(moonshotChunkChoice{
+			Delta:        &chatMessage{},
+			FinishReason: ptr(stopReason),
+			Usage:        &chatUsage,
+		})
+		stopChan <- true
+	}()
+
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case data := <-dataChan:
+			ctx.Render(-1, streamEvent{Data: "data: " + data})
+			return true
+		case <-stopChan:
+			ctx.Render(-1, streamEvent{Data: "data: [DONE]"})
+			return false
+		}
+	})
+}
+
+// moonshotEstimateTokens estimates the number of tokens of the text as one token per character.
+func moonshotEstimateTokens(text string) int {
+	return utf8.RuneCountInString(text)
+}
+
+// moonshotFileStore keeps the uploaded files in memory in the order of upload.
+type moonshotFileStore struct {
+	mutex sync.Mutex
+	ids   []string
+	files map[string]*moonshotStoredFile
+}
+
+type moonshotStoredFile struct {
+	file     moonshotFile
+	fileType string
+	content  string
+}
+
+func newMoonshotFileStore() *moonshotFileStore {
+	return &moonshotFileStore{files: map[string]*moonshotStoredFile{}}
+}
+
+// add stores the file and extracts its text, or returns false if the file count limit is reached. The content of
+// text files is used as is, while the text of binary files like PDFs is mocked.
+func (s *moonshotFileStore) add(filename, purpose, fileType string, data []byte) (*moonshotStoredFile, bool) {
+	content := string(data)
+	if !utf8.Valid(data) {
+		content = fmt.Sprintf("This is the mock content extracted from %s.", filename)
+	}
+	if fileType == "" {
+		fileType = http.DetectContentType(data)
+	}
+	stored := &moonshotStoredFile{
+		file: moonshotFile{
+			Id:        provider.RandomToken(moonshotFileIdPrefix),
+			Object:    moonshotObjectFile,
+			Bytes:     len(data),
+			CreatedAt: time.Now().Unix(),
+			Filename:  filename,
+			Purpose:   purpose,
+			Status:    moonshotFileStatusOk,
+		},
+		fileType: fileType,
+		content:  content,
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if len(s.ids) >= moonshotMaxFileCount {
+		return nil, false
+	}
+	s.ids = append(s.ids, stored.file.Id)
+	s.files[stored.file.Id] = stored
+	return stored, true
+}
+
+func (s *moonshotFileStore) get(id string) (*moonshotStoredFile, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	stored, found := s.files[id]
+	return stored, found
+}
+
+func (s *moonshotFileStore) list() []moonshotFile {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	files := make([]moonshotFile, 0, len(s.ids))
+	for _, id := range s.ids {
+		files = append(files, s.files[id].file)
+	}
+	return files
+}
+
+func (s *moonshotFileStore) delete(id string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, found := s.files[id]; !found {
+		return false
+	}
+	delete(s.files, id)
+	for i, storedId := range s.ids {
+		if storedId == id {
+			s.ids = append(s.ids[:i], s.ids[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// containsContent reports whether the text contains the content of any stored file.
+func (s *moonshotFileStore) containsContent(text string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, stored := range s.files {
+		if stored.content != "" && strings.Contains(text, stored.content) {
+			return true
+		}
+	}
+	return false
+}
+
+type moonshotFile struct {
+	Id            string `json:"id"`
+	Object        string `json:"object"`
+	Bytes         int    `json:"bytes"`
+	CreatedAt     int64  `json:"created_at"`
+	Filename      string `json:"filename"`
+	Purpose       string `json:"purpose"`
+	Status        string `json:"status"`
+	StatusDetails string `json:"status_details"`
+}
+
+type moonshotFileList struct {
+	Object string         `json:"object"`
+	Data   []moonshotFile `json:"data"`
+}
+
+type moonshotFileDeleted struct {
+	Id      string `json:"id"`
+	Object  string `json:"object"`
+	Deleted bool   `json:"deleted"`
+}
+
+type moonshotFileContent struct {
+	Content  string `json:"content"`
+	FileType string `json:"file_type"`
+	Filename string `json:"filename"`
+	Title    string `json:"title"`
+	Type     string `json:"type"`
+}
+
+type moonshotChatCompletionChunk struct {
+	Id      string                `json:"id"`
+	Object  string                `json:"object"`
+	Created int64                 `json:"created"`
+	Model   string                `json:"model"`
+	Choices []moonshotChunkChoice `json:"choices"`
+}
+
+type moonshotChunkChoice struct {
+	Index        int          `json:"index"`
+	Delta        *chatMessage `json:"delta"`
+	FinishReason *string      `json:"finish_reason,omitempty"`
+	Usage        *usage       `json:"usage,omitempty"`
+}
+
+type moonshotErrorResp struct {
+	Error moonshotError `json:"error"`
+}
+
+type moonshotError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,300 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"llm-mock-server/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	ollamaChatPath     = "/api/chat"
+	ollamaGeneratePath = "/api/generate"
+	ollamaTagsPath     = "/api/tags"
+	ollamaShowPath     = "/api/show"
+
+	ollamaDoneReasonStop = "stop"
+
+	// Mock timings in nanoseconds
+	ollamaMockTotalDuration      = 5_000_000_000
+	ollamaMockLoadDuration       = 1_000_000_000
+	ollamaMockPromptEvalDuration = 1_000_000_000
+	ollamaMockEvalDuration       = 3_000_000_000
+)
+
+var (
+	ollamaMockCreatedAt = time.Unix(completionMockCreated, 0).UTC().Format(time.RFC3339Nano)
+
+	ollamaMockModels = []ollamaModel{
+		{
+			Name:       "llama3.2:latest",
+			Model:      "llama3.2:latest",
+			ModifiedAt: ollamaMockCreatedAt,
+			Size:       2019393189,
+			Digest:     "a80c4f17acd55265feec403c7aef86be0c25983ab279d83f3bcd3abbcb5b8b72",
+			Details: ollamaModelDetails{
+				Format:            "gguf",
+				Family:            "llama",
+				Families:          []string{"llama"},
+				ParameterSize:     "3.2B",
+				QuantizationLevel: "Q4_K_M",
+			},
+		},
+		{
+			Name:       "qwen2.5:7b",
+			Model:      "qwen2.5:7b",
+			ModifiedAt: ollamaMockCreatedAt,
+			Size:       4683087332,
+			Digest:     "845dbda0ea48ed749caafd9e6037047aa19acfcfd82e704d7ca97d631a0b697e",
+			Details: ollamaModelDetails{
+				Format:            "gguf",
+				Family:            "qwen2",
+				Families:          []string{"qwen2"},
+				ParameterSize:     "7.6B",
+				QuantizationLevel: "Q4_K_M",
+			},
+		},
+		{
+			Name:       "all-minilm:latest",
+			Model:      "all-minilm:latest",
+			ModifiedAt: ollamaMockCreatedAt,
+			Size:       45960996,
+			Digest:     "1b226e2802dbb772b5fc32a58f103ca1804ef7501331012de126ab22f67475ef",
+			Details: ollamaModelDetails{
+				Format:            "gguf",
+				Family:            "bert",
+				Families:          []string{"bert"},
+				ParameterSize:     "23M",
+				QuantizationLevel: "F16",
+			},
+		},
+	}
+)
+
+// ollamaProvider emulates the native Ollama API, which streams newline-delimited JSON by default.
+type ollamaProvider struct{}
+
+func (p *ollamaProvider) ShouldHandleRequest(ctx *gin.Context) bool {
+	context, _ := getRequestContext(ctx)
+	return context.Path == ollamaChatPath || context.Path == ollamaGeneratePath
+}
+
+func (p *ollamaProvider) registerRoutes(server *gin.Engine) {
+	server.GET(ollamaTagsPath, p.handleTags)
+	server.POST(ollamaShowPath, p.handleShow)
+}
+
+func (p *ollamaProvider) HandleChatCompletions(ctx *gin.Context) {
+	context, _ := getRequestContext(ctx)
+
+	// Bind request body
+	var chatRequest ollamaRequest
+	if err := ctx.ShouldBindJSON(&chatRequest); err != nil {
+		p.sendErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Validate request body
+	if err := utils.Validate.Struct(chatRequest); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		for _, fieldError := range validationErrors {
+			p.sendErrorResponse(ctx, http.StatusBadRequest, fieldError.Error())
+			return
+		}
+	}
+
+	var prompt string
+	isChat := context.Path == ollamaChatPath
+	if isChat {
+		if len(chatRequest.Messages) == 0 {
+			p.sendErrorResponse(ctx, http.StatusBadRequest, "messages is required")
+			return
+		}
+		prompt = chatRequest.Messages[len(chatRequest.Messages)-1].Content
+	} else {
+		prompt = chatRequest.Prompt
+	}
+	response := prompt2Response(prompt)
+
+	// Unlike other providers, Ollama streams unless stream is explicitly set to false
+	if chatRequest.Stream == nil || *chatRequest.Stream {
+		p.handleStreamResponse(ctx, chatRequest, isChat, response)
+	} else {
+		p.handleNonStreamResponse(ctx, chatRequest, isChat, response)
+	}
+}
+
+func (p *ollamaProvider) sendErrorResponse(ctx *gin.Context, statusCode int, message string) {
+	ctx.JSON(statusCode, gin.H{"error": message})
+}
+
+func (p *ollamaProvider) handleStreamResponse(ctx *gin.Context, chatRequest ollamaRequest, isChat bool, response string) {
+	ctx.Writer.Header().Set("Content-Type", "application/x-ndjson")
+	dataChan := make(chan string)
+	stopChan := make(chan bool, 1)
+
+	go func() {
+		for _, s := range response {
+			streamResponse := p.createResponse(chatRequest.Model, isChat, string(s))
+			jsonStr, _ := json.Marshal(streamResponse)
+			dataChan <- string(jsonStr)
+
+			// Simulate response delay
+			time.Sleep(200 * time.Millisecond)
+		}
+		stopChan <- true
+	}()
+
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case data := <-dataChan:
+			ctx.Render(-1, ndjsonEvent{Data: data})
+			return true
+		case <-stopChan:
+			// The final object carries no content but the done reason and the statistics
+			finalResponse := p.createResponse(chatRequest.Model, isChat, "")
+			p.setDone(&finalResponse)
+			jsonStr, _ := json.Marshal(finalResponse)
+			ctx.Render(-1, ndjsonEvent{Data: string(jsonStr)})
+			return false
+		}
+	})
+}
+
+func (p *ollamaProvider) handleNonStreamResponse(ctx *gin.Context, chatRequest ollamaRequest, isChat bool, response string) {
+	completion := p.createResponse(chatRequest.Model, isChat, response)
+	p.setDone(&completion)
+	ctx.JSON(http.StatusOK, completion)
+}
+
+func (p *ollamaProvider) createResponse(model string, isChat bool, response string) ollamaResponse {
+	ollamaResponse := ollamaResponse{
+		Model:     model,
+		CreatedAt: ollamaMockCreatedAt,
+	}
+	if isChat {
+		ollamaResponse.Message = &ollamaMessage{Role: roleAssistant, Content: response}
+	} else {
+		ollamaResponse.Response = ptr(response)
+	}
+	return ollamaResponse
+}
+
+func (p *ollamaProvider) setDone(response *ollamaResponse) {
+	response.Done = true
+	response.DoneReason = ollamaDoneReasonStop
+	response.TotalDuration = ollamaMockTotalDuration
+	response.LoadDuration = ollamaMockLoadDuration
+	response.PromptEvalCount = completionMockUsage.PromptTokens
+	response.PromptEvalDuration = ollamaMockPromptEvalDuration
+	response.EvalCount = completionMockUsage.CompletionTokens
+	response.EvalDuration = ollamaMockEvalDuration
+}
+
+func (p *ollamaProvider) handleTags(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"models": ollamaMockModels})
+}
+
+func (p *ollamaProvider) handleShow(ctx *gin.Context) {
+	var showRequest ollamaShowRequest
+	if err := ctx.ShouldBindJSON(&showRequest); err != nil {
+		p.sendErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+	if showRequest.Model == "" {
+		p.sendErrorResponse(ctx, http.StatusBadRequest, "model is required")
+		return
+	}
+
+	details := ollamaMockModels[0].Details
+	for _, model := range ollamaMockModels {
+		if model.Name == showRequest.Model || model.Model == showRequest.Model {
+			details = model.Details
+			break
+		}
+	}
+	ctx.JSON(http.StatusOK, ollamaShowResponse{
+		Modelfile:    fmt.Sprintf("FROM %s\nTEMPLATE \"{{ .Prompt }}\"", showRequest.Model),
+		Parameters:   "stop \"<|eot_id|>\"",
+		Template:     "{{ .Prompt }}",
+		Details:      details,
+		ModelInfo:    map[string]any{"general.architecture": details.Family},
+		Capabilities: []string{"completion"},
+		ModifiedAt:   ollamaMockCreatedAt,
+	})
+}
+
+type ollamaRequest struct {
+	Model     string          `json:"model" validate:"required"`
+	Messages  []ollamaMessage `json:"messages,omitempty"`
+	Prompt    string          `json:"prompt,omitempty"`
+	Suffix    string          `json:"suffix,omitempty"`
+	System    string          `json:"system,omitempty"`
+	Raw       bool            `json:"raw,omitempty"`
+	Stream    *bool           `json:"stream,omitempty"`
+	Format    any             `json:"format,omitempty"`
+	Options   map[string]any  `json:"options,omitempty"`
+	KeepAlive any             `json:"keep_alive,omitempty"`
+	Tools     []tool          `json:"tools,omitempty"`
+}
+
+type ollamaMessage struct {
+	Role    string   `json:"role"`
+	Content string   `json:"content"`
+	Images  []string `json:"images,omitempty"`
+}
+
+// ollamaResponse is the response of both /api/chat, which returns the message, and /api/generate, which returns the response.
+type ollamaResponse struct {
+	Model              string         `json:"model"`
+	CreatedAt          string         `json:"created_at"`
+	Message            *ollamaMessage `json:"message,omitempty"`
+	Response           *string        `json:"response,omitempty"`
+	Done               bool           `json:"done"`
+	DoneReason         string         `json:"done_reason,omitempty"`
+	TotalDuration      int64          `json:"total_duration,omitempty"`
+	LoadDuration       int64          `json:"load_duration,omitempty"`
+	PromptEvalCount    int            `json:"prompt_eval_count,omitempty"`
+	PromptEvalDuration int64          `json:"prompt_eval_duration,omitempty"`
+	EvalCount          int            `json:"eval_count,omitempty"`
+	EvalDuration       int64          `json:"eval_duration,omitempty"`
+}
+
+type ollamaModel struct {
+	Name       string             `json:"name"`
+	Model      string             `json:"model"`
+	ModifiedAt string             `json:"modified_at"`
+	Size       int64              `json:"size"`
+	Digest     string             `json:"digest"`
+	Details    ollamaModelDetails `json:"details"`
+}
+
+type ollamaModelDetails struct {
+	ParentModel       string   `json:"parent_model"`
+	Format            string   `json:"format"`
+	Family            string   `json:"family"`
+	Families          []string `json:"families"`
+	ParameterSize     string   `json:"parameter_size"`
+	QuantizationLevel string   `json:"quantization_level"`
+}
+
+type ollamaShowRequest struct {
+	Model   string `json:"model"`
+	Verbose bool   `json:"verbose,omitempty"`
+}
+
+type ollamaShowResponse struct {
+	Modelfile    string             `json:"modelfile"`
+	Parameters   string             `json:"parameters"`
+	Template     string             `json:"template"`
+	Details      ollamaModelDetails `json:"details"`
+	ModelInfo    map[string]any     `json:"model_info"`
+	Capabilities []string           `json:"capabilities"`
+	ModifiedAt   string             `json:"modified_at"`
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"llm-mock-server/pkg/utils"
@@ -12,7 +13,11 @@ import (
 	"github.com/go-playground/validator/v10"
 )
 
-type openAiProvider struct{}
+type openAiProvider struct {
+	// inlineThink makes the visible reasoning returned inline in the content wrapped in <think> tags
+	// instead of in reasoning_content.
+	inlineThink bool
+}
 
 func (p *openAiProvider) ShouldHandleRequest(ctx *gin.Context) bool {
 	return true
@@ -48,6 +53,21 @@ func (p *openAiProvider) HandleChatCompletions(ctx *gin.Context) {
 	}
 }
 
+// createReasoning returns the reasoning of the response if the request is for a reasoning model, and whether the
+// reasoning is visible. DeepSeek-R1 and Qwen thinking models return the reasoning, while OpenAI reasoning models
+// only count it in the usage.
+func (p *openAiProvider) createReasoning(chatRequest chatCompletionRequest, response string) (*mockReasoning, bool) {
+	model := strings.ToLower(chatRequest.Model)
+	if strings.Contains(model, "deepseek-r1") || strings.Contains(model, "deepseek-reasoner") ||
+		(chatRequest.EnableThinking != nil && *chatRequest.EnableThinking) {
+		return newMockReasoning(response, chatRequest.ThinkingBudget), true
+	}
+	if budget, ok := reasoningEffortBudgets[chatRequest.ReasoningEffort]; ok {
+		return newMockReasoning(response, budget), false
+	}
+	return nil, false
+}
+
 func (p *openAiProvider) handleStreamResponse(ctx *gin.Context, chatRequest chatCompletionRequest, response string) {
 	utils.SetEventStreamHeaders(ctx)
 	dataChan := make(chan string)
@@ -58,21 +78,50 @@ func (p *openAiProvider) handleStreamResponse(ctx *gin.Context, chatRequest chat
 		Created: completionMockCreated,
 		Model:   chatRequest.Model,
 	}
-	streamResponseChoice := chatCompletionChoice{Delta: &chatMessage{}}
+	reasoning, visible := p.createReasoning(chatRequest, response)
 	go func() {
+		sendDelta := func(delta chatMessage, finishReason *string) {
+			streamResponse.Choices = []chatCompletionChoice{{Delta: &delta, FinishReason: finishReason}}
+			jsonStr, _ := json.Marshal(streamResponse)
+			dataChan <- string(jsonStr)
+		}
+
+		// The reasoning is streamed before the content
+		if reasoning != nil && visible {
+			chunks := reasoning.Chunks()
+			if p.inlineThink {
+				chunks[0] = thinkTagOpen + chunks[0]
+				chunks[len(chunks)-1] += thinkTagClose
+			}
+			for _, chunk := range chunks {
+				if p.inlineThink {
+					sendDelta(chatMessage{Content: chunk}, nil)
+				} else {
+					sendDelta(chatMessage{ReasoningContent: chunk}, nil)
+				}
+				time.Sleep(reasoningMockDelay)
+			}
+		}
+
 		responseRunes := []rune(response)
 		for i, s := range responseRunes {
-			streamResponseChoice.Delta.Content = string(s)
+			var finishReason *string
 			if i == len(responseRunes)-1 {
-				streamResponseChoice.FinishReason = ptr(stopReason)
+				finishReason = ptr(stopReason)
 			}
-			streamResponse.Choices = []chatCompletionChoice{streamResponseChoice}
-			jsonStr, _ := json.Marshal(streamResponse)
-			dataChan <- string(jsonStr)
+			sendDelta(chatMessage{Content: string(s)}, finishReason)
 
 			// Simulate response delay
 			time.Sleep(200 * time.Millisecond)
 		}
+
+		// The usage is sent in the last chunk without choices if requested
+		if chatRequest.StreamOptions != nil && chatRequest.StreamOptions.IncludeUsage {
+			streamResponse.Choices = []chatCompletionChoice{}
+			streamResponse.Usage = createReasoningUsage(reasoning)
+			jsonStr, _ := json.Marshal(streamResponse)
+			dataChan <- string(jsonStr)
+		}
 		stopChan <- true
 	}()
 
@@ -90,9 +139,31 @@ func (p *openAiProvider) handleStreamResponse(ctx *gin.Context, chatRequest chat
 
 func (p *openAiProvider) handleNonStreamResponse(ctx *gin.Context, chatRequest chatCompletionRequest, response string) {
 	completion := createChatCompletionResponse(chatRequest.Model, response)
+	if reasoning, visible := p.createReasoning(chatRequest, response); reasoning != nil {
+		if visible {
+			message := completion.Choices[0].Message
+			if p.inlineThink {
+				message.Content = thinkTagOpen + reasoning.Text + thinkTagClose + response
+			} else {
+				message.ReasoningContent = reasoning.Text
+			}
+		}
+		completion.Usage = createReasoningUsage(reasoning)
+	}
 	ctx.JSON(http.StatusOK, completion)
 }
 
+// createReasoningUsage returns the usage in which the completion tokens include the reasoning tokens if any.
+func createReasoningUsage(reasoning *mockReasoning) *usage {
+	reasoningUsage := completionMockUsage
+	if reasoning != nil {
+		reasoningUsage.CompletionTokens += reasoning.Tokens
+		reasoningUsage.TotalTokens += reasoning.Tokens
+		reasoningUsage.CompletionTokensDetails = &completionTokensDetails{ReasoningTokens: reasoning.Tokens}
+	}
+	return &reasoningUsage
+}
+
 func createChatCompletionResponse(model, response string) chatCompletionResponse {
 	return chatCompletionResponse{
 		Id:      completionMockId,
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"strings"
 
+	"llm-mock-server/pkg/cmd/options"
 	"llm-mock-server/pkg/log"
 	"llm-mock-server/pkg/provider"
 
@@ -19,17 +20,33 @@ type requestHandler interface {
 	HandleChatCompletions(context *gin.Context)
 }
 
+// routesRegistrar is implemented by providers which also serve endpoints other than chat completions,
+// e.g. the token endpoint issuing the credentials of chat completion requests.
+type routesRegistrar interface {
+	registerRoutes(server *gin.Engine)
+}
+
 var (
-	chatCompletionsHandlers = []requestHandler{
-		&minimaxProvider{},
-		&difyProvider{},
-		&qwenProvider{},
-		&openAiProvider{}, // As the last fallback
-	}
+	chatCompletionsHandlers []requestHandler
 
 	chatCompletionsRoutes = []string{
+		// azure
+		"/openai/deployments/:deployment/chat/completions",
 		// baidu
 		"/v2/chat/completions",
+		"/rpc/2.0/ai_custom/v1/wenxinworkshop/chat/:model",
+		// bedrock
+		"/model/:modelId/converse",
+		"/model/:modelId/converse-stream",
+		"/model/:modelId/invoke",
+		"/model/:modelId/invoke-with-response-stream",
+		// claude
+		"/v1/messages",
+		// cloudflare
+		"/client/v4/accounts/:accountId/ai/run/*model",
+		"/client/v4/accounts/:accountId/ai/v1/chat/completions",
+		// cohere
+		"/v2/chat",
 		// doubao
 		"/api/v3/chat/completions",
 		// github
@@ -39,23 +56,90 @@ var (
 		// minimax
 		"/v1/text/chatcompletion_v2",
 		"/v1/text/chatcompletion_pro",
+		// ollama
+		"/api/chat",
+		"/api/generate",
 		// openai
 		"/v1/chat/completions",
+		"/v1/completions",
+		"/v1/responses",
 		// qwen
 		"/compatible-mode/v1/chat/completions",
 		"/api/v1/services/aigc/text-generation/generation",
+		// tgi
+		"/generate",
+		"/generate_stream",
 		// zhipu
 		"/api/paas/v4/chat/completions",
 		// dify
 		"/v1/completion-messages",
 		"/v1/chat-messages",
+		"/v1/workflows/run",
 	}
 )
 
-func SetupRoutes(server *gin.Engine) {
+func newChatCompletionsHandlers(option *options.Option, googleAuth *provider.GoogleAuth) []requestHandler {
+	openAi := &openAiProvider{inlineThink: option.InlineThink}
+	return []requestHandler{
+		&cloudflareProvider{
+			accountId: option.CloudflareAccountId,
+			apiToken:  option.CloudflareApiToken,
+			openAi:    openAi,
+		},
+		&minimaxProvider{},
+		&difyProvider{defaultAppType: option.DifyAppType},
+		&qwenProvider{},
+		&claudeProvider{},
+		&geminiProvider{auth: googleAuth},
+		newVertexProvider(googleAuth, option.VertexAccessTokenTTL),
+		&azureProvider{},
+		&bedrockProvider{
+			accessKeyId:     option.AwsAccessKeyId,
+			secretAccessKey: option.AwsSecretAccessKey,
+		},
+		&baiduProvider{
+			clientId:       option.BaiduClientId,
+			clientSecret:   option.BaiduClientSecret,
+			accessTokenTTL: option.BaiduAccessTokenTTL,
+			accessTokens:   provider.NewAccessTokenStore(),
+		},
+		&zhipuProvider{
+			apiKey: option.ZhipuApiKey,
+			openAi: openAi,
+		},
+		&hunyuanProvider{
+			secretId:  option.HunyuanSecretId,
+			secretKey: option.HunyuanSecretKey,
+		},
+		&moonshotProvider{files: newMoonshotFileStore()},
+		&ollamaProvider{},
+		&cohereProvider{},
+		&tgiProvider{},
+		&responsesProvider{responses: newResponseStore()},
+		&textCompletionProvider{},
+		openAi, // As the last fallback
+	}
+}
+
+// SetupRoutes registers the chat completion routes, whose Google API requests are authenticated by googleAuth.
+func SetupRoutes(server *gin.Engine, option *options.Option, googleAuth *provider.GoogleAuth) {
+	chatCompletionsHandlers = newChatCompletionsHandlers(option, googleAuth)
 	for _, route := range chatCompletionsRoutes {
 		server.POST(route, handleChatCompletions)
 	}
+	for _, handler := range chatCompletionsHandlers {
+		if registrar, ok := handler.(routesRegistrar); ok {
+			registrar.registerRoutes(server)
+		}
+	}
+
+	// Spark streams chat completions over WebSocket instead of HTTP
+	spark := &sparkProvider{
+		appId:     option.SparkAppId,
+		apiKey:    option.SparkApiKey,
+		apiSecret: option.SparkApiSecret,
+	}
+	spark.registerRoutes(server)
 }
 
 func handleChatCompletions(context *gin.Context) {
@@ -71,6 +155,21 @@ func handleChatCompletions(context *gin.Context) {
 	context.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
 }
 
+// handleProviderChatCompletions returns the handler of a route served only by the given provider, which responds
+// 404 to the requests the provider does not handle instead of falling back to the other providers.
+func handleProviderChatCompletions(handler requestHandler) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		if err := buildRequestContext(context); err != nil {
+			return
+		}
+		if !handler.ShouldHandleRequest(context) {
+			context.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+			return
+		}
+		handler.HandleChatCompletions(context)
+	}
+}
+
 type requestContext struct {
 	Host  string
 	Path  string
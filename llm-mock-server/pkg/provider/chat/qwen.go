@@ -1,8 +1,12 @@
 package chat
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -13,6 +17,10 @@ const (
 	qwenDomain              = "dashscope.aliyuncs.com"
 	qwenChatCompletionPath  = "/api/v1/services/aigc/text-generation/generation"
 	qwenResultFormatMessage = "message"
+	// qwenFinishReasonNull is the finish reason of all but the last chunk of a stream response.
+	qwenFinishReasonNull  = "null"
+	qwenStreamEventResult = "result"
+	qwenStreamHttpStatus  = "HTTP_STATUS/200"
 )
 
 type qwenProvider struct {
@@ -63,8 +71,19 @@ func (p *qwenProvider) HandleChatCompletions(ctx *gin.Context) {
 	// Determine if the request is a stream request
 	isStream := p.isStreamRequest(ctx)
 
+	// Thinking models only support streaming output
+	var reasoning *mockReasoning
+	if chatRequest.Parameters.EnableThinking {
+		if !isStream {
+			p.sendErrorResponse(ctx, http.StatusBadRequest,
+				"InvalidParameter", "parameter.enable_thinking must be set to false for non-streaming calls")
+			return
+		}
+		reasoning = newMockReasoning(response, chatRequest.Parameters.ThinkingBudget)
+	}
+
 	if isStream {
-		// todo stream response
+		p.handleStreamResponse(ctx, chatRequest, response, reasoning)
 	} else {
 		p.handleNonStreamResponse(ctx, chatRequest, response)
 	}
@@ -91,8 +110,87 @@ func (p *qwenProvider) isStreamRequest(ctx *gin.Context) bool {
 	return false
 }
 
+func (p *qwenProvider) handleStreamResponse(ctx *gin.Context, chatRequest qwenTextGenRequest, response string, reasoning *mockReasoning) {
+	utils.SetEventStreamHeaders(ctx)
+	dataChan := make(chan streamEvent)
+	stopChan := make(chan bool, 1)
+
+	go func() {
+		eventId := 0
+		send := func(streamResponse qwenTextGenResponse, outputTokens, reasoningTokens int) {
+			// Usage is reported on every chunk, with output tokens accumulated so far
+			streamResponse.Usage.OutputTokens = outputTokens
+			streamResponse.Usage.TotalTokens = streamResponse.Usage.InputTokens + streamResponse.Usage.OutputTokens
+			if reasoning != nil {
+				streamResponse.Usage.OutputTokensDetails = &qwenOutputTokensDetails{ReasoningTokens: reasoningTokens}
+			}
+			jsonStr, _ := json.Marshal(streamResponse)
+			eventId++
+			dataChan <- streamEvent{
+				Id:      strconv.Itoa(eventId),
+				Event:   qwenStreamEventResult,
+				Comment: qwenStreamHttpStatus,
+				Data:    "data:" + string(jsonStr),
+			}
+		}
+
+		// The reasoning is streamed before the content, and only counted in the usage in the text format,
+		// which has no field for the reasoning content
+		reasoningTokens := 0
+		if reasoning != nil {
+			var reasoningSoFar string
+			for _, chunk := range reasoning.Chunks() {
+				reasoningTokens++
+				if chatRequest.Parameters.ResultFormat != qwenResultFormatMessage {
+					continue
+				}
+				reasoningSoFar += chunk
+				if !chatRequest.Parameters.IncrementalOutput {
+					chunk = reasoningSoFar
+				}
+				streamResponse := createQwenTextGenResponse(chatRequest, "", chunk)
+				streamResponse.setFinishReason(qwenFinishReasonNull)
+				send(streamResponse, reasoningTokens, reasoningTokens)
+				time.Sleep(reasoningMockDelay)
+			}
+		}
+
+		responseRunes := []rune(response)
+		for i, s := range responseRunes {
+			// Without incremental_output, each chunk carries all the text generated so far
+			chunk := string(s)
+			if !chatRequest.Parameters.IncrementalOutput {
+				chunk = string(responseRunes[:i+1])
+			}
+			streamResponse := createQwenTextGenResponse(chatRequest, chunk, "")
+			if i != len(responseRunes)-1 {
+				streamResponse.setFinishReason(qwenFinishReasonNull)
+			}
+			send(streamResponse, reasoningTokens+i+1, reasoningTokens)
+
+			// Simulate response delay
+			time.Sleep(200 * time.Millisecond)
+		}
+		// The stream always ends with the stop event, even if there is no content
+		if len(responseRunes) == 0 {
+			send(createQwenTextGenResponse(chatRequest, "", ""), reasoningTokens, reasoningTokens)
+		}
+		stopChan <- true
+	}()
+
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case event := <-dataChan:
+			ctx.Render(-1, event)
+			return true
+		case <-stopChan:
+			return false
+		}
+	})
+}
+
 func (p *qwenProvider) handleNonStreamResponse(ctx *gin.Context, chatRequest qwenTextGenRequest, response string) {
-	completion := createQwenTextGenResponse(chatRequest, response)
+	completion := createQwenTextGenResponse(chatRequest, response, "")
 	ctx.JSON(http.StatusOK, completion)
 }
 
@@ -122,6 +220,8 @@ type qwenTextGenParameters struct {
 	TopP              float64 `json:"top_p,omitempty"`
 	IncrementalOutput bool    `json:"incremental_output,omitempty"`
 	EnableSearch      bool    `json:"enable_search,omitempty"`
+	EnableThinking    bool    `json:"enable_thinking,omitempty"`
+	ThinkingBudget    int     `json:"thinking_budget,omitempty"`
 	Tools             []tool  `json:"tools,omitempty"`
 }
 
@@ -131,7 +231,7 @@ type qwenTextGenResponse struct {
 	Usage     qwenUsage         `json:"usage"`
 }
 
-func createQwenTextGenResponse(chatRequest qwenTextGenRequest, response string) qwenTextGenResponse {
+func createQwenTextGenResponse(chatRequest qwenTextGenRequest, response, reasoningContent string) qwenTextGenResponse {
 	var output qwenTextGenOutput
 	if chatRequest.Parameters.ResultFormat == qwenResultFormatMessage {
 		output = qwenTextGenOutput{
@@ -139,8 +239,9 @@ func createQwenTextGenResponse(chatRequest qwenTextGenRequest, response string)
 				{
 					FinishReason: stopReason,
 					Message: qwenMessage{
-						Role:    roleAssistant,
-						Content: response,
+						Role:             roleAssistant,
+						Content:          response,
+						ReasoningContent: reasoningContent,
 					},
 				},
 			},
@@ -162,6 +263,16 @@ func createQwenTextGenResponse(chatRequest qwenTextGenRequest, response string)
 	}
 }
 
+func (r *qwenTextGenResponse) setFinishReason(finishReason string) {
+	if r.Output.Choices == nil {
+		r.Output.FinishReason = finishReason
+		return
+	}
+	for i := range r.Output.Choices {
+		r.Output.Choices[i].FinishReason = finishReason
+	}
+}
+
 type qwenTextGenOutput struct {
 	FinishReason string              `json:"finish_reason,omitempty"`
 	Text         string              `json:"text,omitempty"`
@@ -174,16 +285,22 @@ type qwenTextGenChoice struct {
 }
 
 type qwenUsage struct {
-	InputTokens  int `json:"input_tokens"`
-	OutputTokens int `json:"output_tokens"`
-	TotalTokens  int `json:"total_tokens"`
+	InputTokens         int                      `json:"input_tokens"`
+	OutputTokens        int                      `json:"output_tokens"`
+	TotalTokens         int                      `json:"total_tokens"`
+	OutputTokensDetails *qwenOutputTokensDetails `json:"output_tokens_details,omitempty"`
+}
+
+type qwenOutputTokensDetails struct {
+	ReasoningTokens int `json:"reasoning_tokens"`
 }
 
 type qwenMessage struct {
-	Name      string     `json:"name,omitempty"`
-	Role      string     `json:"role"`
-	Content   any        `json:"content"`
-	ToolCalls []toolCall `json:"tool_calls,omitempty"`
+	Name             string     `json:"name,omitempty"`
+	Role             string     `json:"role"`
+	Content          any        `json:"content"`
+	ReasoningContent string     `json:"reasoning_content,omitempty"`
+	ToolCalls        []toolCall `json:"tool_calls,omitempty"`
 }
 
 func (m *qwenMessage) IsStringContent() bool {
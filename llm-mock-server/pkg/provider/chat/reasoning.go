@@ -0,0 +1,64 @@
+package chat
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// reasoningBudgetPerWord is the number of budget tokens spent on each word of the mock reasoning, so that
+	// the reasoning length follows the budget while large budgets still stream quickly.
+	reasoningBudgetPerWord = 16
+	reasoningDefaultBudget = 1024
+	// reasoningMockDelay is the delay between reasoning chunks, which is shorter than the one of content chunks
+	// since reasoning is much longer.
+	reasoningMockDelay = 20 * time.Millisecond
+
+	thinkTagOpen  = "<think>\n"
+	thinkTagClose = "\n</think>\n\n"
+)
+
+// reasoningEffortBudgets maps the OpenAI reasoning_effort to the reasoning budget in tokens.
+var reasoningEffortBudgets = map[string]int{
+	"minimal": reasoningBudgetPerWord,
+	"low":     reasoningDefaultBudget / 2,
+	"medium":  reasoningDefaultBudget,
+	"high":    reasoningDefaultBudget * 2,
+}
+
+// mockReasoning is the reasoning generated before the response. Each word is counted as one reasoning token.
+type mockReasoning struct {
+	Text   string
+	Tokens int
+}
+
+// newMockReasoning returns the reasoning about the prompt within the budget, with one word per reasoningBudgetPerWord tokens.
+func newMockReasoning(prompt string, budget int) *mockReasoning {
+	if budget <= 0 {
+		budget = reasoningDefaultBudget
+	}
+	wordCount := max(budget/reasoningBudgetPerWord, 1)
+	template := strings.Fields(fmt.Sprintf("Okay, the user said %q, so I should answer with exactly the same text.", prompt))
+	words := make([]string, wordCount)
+	for i := range words {
+		words[i] = template[i%len(template)]
+	}
+	return &mockReasoning{
+		Text:   strings.Join(words, " "),
+		Tokens: wordCount,
+	}
+}
+
+// Chunks splits the reasoning into the chunks to stream, one word per chunk.
+func (r *mockReasoning) Chunks() []string {
+	return strings.SplitAfter(r.Text, " ")
+}
+
+// Signature returns the mock signature of the reasoning, which lets clients pass the reasoning back unmodified.
+func (r *mockReasoning) Signature() string {
+	digest := sha256.Sum256([]byte(r.Text))
+	return base64.StdEncoding.EncodeToString(digest[:])
+}
@@ -0,0 +1,537 @@
+package chat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"llm-mock-server/pkg/provider"
+	"llm-mock-server/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	responsesPath       = "/v1/responses"
+	responsesIdPath     = "/v1/responses/:id"
+	responsesCancelPath = "/v1/responses/:id/cancel"
+
+	responseIdPrefix = "resp_"
+	messageIdPrefix  = "msg_"
+
+	objectResponse = "response"
+
+	responseStatusQueued     = "queued"
+	responseStatusInProgress = "in_progress"
+	responseStatusCompleted  = "completed"
+	responseStatusCancelled  = "cancelled"
+
+	responseItemTypeMessage         = "message"
+	responseContentTypeOutputText   = "output_text"
+	responseTextFormatTypeText      = "text"
+	responseToolChoiceAuto          = "auto"
+	responseErrorTypeInvalidRequest = "invalid_request_error"
+
+	responseEventCreated          = "response.created"
+	responseEventInProgress       = "response.in_progress"
+	responseEventOutputItemAdded  = "response.output_item.added"
+	responseEventContentPartAdded = "response.content_part.added"
+	responseEventOutputTextDelta  = "response.output_text.delta"
+	responseEventOutputTextDone   = "response.output_text.done"
+	responseEventContentPartDone  = "response.content_part.done"
+	responseEventOutputItemDone   = "response.output_item.done"
+	responseEventCompleted        = "response.completed"
+
+	// responseMockDelayPerRune is the time the mock takes to generate each rune, both when streaming
+	// and when running in the background.
+	responseMockDelayPerRune = 200 * time.Millisecond
+)
+
+// responsesProvider emulates the OpenAI Responses API, which keeps the created responses so that they can be
+// retrieved, deleted, cancelled or continued by previous_response_id.
+type responsesProvider struct {
+	responses *responseStore
+}
+
+func (p *responsesProvider) ShouldHandleRequest(ctx *gin.Context) bool {
+	context, _ := getRequestContext(ctx)
+	return context.Path == responsesPath
+}
+
+func (p *responsesProvider) registerRoutes(server *gin.Engine) {
+	server.GET(responsesIdPath, p.handleGetResponse)
+	server.DELETE(responsesIdPath, p.handleDeleteResponse)
+	server.POST(responsesCancelPath, p.handleCancelResponse)
+}
+
+func (p *responsesProvider) HandleChatCompletions(ctx *gin.Context) {
+	// Bind request body
+	var responsesRequest responsesRequest
+	if err := ctx.ShouldBindJSON(&responsesRequest); err != nil {
+		p.sendErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Validate request body
+	if err := utils.Validate.Struct(responsesRequest); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		for _, fieldError := range validationErrors {
+			p.sendErrorResponse(ctx, http.StatusBadRequest, fieldError.Error())
+			return
+		}
+	}
+
+	store := responsesRequest.Store == nil || *responsesRequest.Store
+	if responsesRequest.Background && !store {
+		p.sendErrorResponse(ctx, http.StatusBadRequest, "Background mode requires store to be true.")
+		return
+	}
+
+	// The input of the previous response is counted as part of the context
+	inputTokens := completionMockUsage.PromptTokens
+	if responsesRequest.PreviousResponseId != "" {
+		previous, found := p.responses.get(responsesRequest.PreviousResponseId)
+		if !found {
+			p.sendErrorResponse(ctx, http.StatusNotFound,
+				fmt.Sprintf("Previous response with id '%s' not found.", responsesRequest.PreviousResponseId))
+			return
+		}
+		if previous.Usage != nil {
+			inputTokens += previous.Usage.TotalTokens
+		}
+	}
+
+	responseText := prompt2Response(responsesRequest.inputText())
+	response := p.createResponse(responsesRequest, store, responseText, inputTokens)
+	if store {
+		completesAt := time.Now()
+		if responsesRequest.Background && !responsesRequest.Stream {
+			completesAt = completesAt.Add(time.Duration(len([]rune(responseText))) * responseMockDelayPerRune)
+		}
+		p.responses.put(response, responsesRequest.Background, completesAt)
+	}
+
+	if responsesRequest.Stream {
+		p.handleStreamResponse(ctx, response, responseText)
+	} else if responsesRequest.Background {
+		queued := p.createPendingResponse(response, responseStatusQueued)
+		ctx.JSON(http.StatusOK, queued)
+	} else {
+		ctx.JSON(http.StatusOK, response)
+	}
+}
+
+func (p *responsesProvider) handleGetResponse(ctx *gin.Context) {
+	response, found := p.responses.get(ctx.Param("id"))
+	if !found {
+		p.sendResponseNotFound(ctx)
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (p *responsesProvider) handleDeleteResponse(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if !p.responses.delete(id) {
+		p.sendResponseNotFound(ctx)
+		return
+	}
+	ctx.JSON(http.StatusOK, responsesDeleteResponse{
+		Id:      id,
+		Object:  objectResponse,
+		Deleted: true,
+	})
+}
+
+// handleCancelResponse cancels a background response which is still in progress.
+func (p *responsesProvider) handleCancelResponse(ctx *gin.Context) {
+	response, found, err := p.responses.cancel(ctx.Param("id"))
+	if !found {
+		p.sendResponseNotFound(ctx)
+		return
+	}
+	if err != nil {
+		p.sendErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (p *responsesProvider) sendResponseNotFound(ctx *gin.Context) {
+	p.sendErrorResponse(ctx, http.StatusNotFound, fmt.Sprintf("Response with id '%s' not found.", ctx.Param("id")))
+}
+
+func (p *responsesProvider) sendErrorResponse(ctx *gin.Context, statusCode int, message string) {
+	ctx.JSON(statusCode, responsesErrorResponse{
+		Error: responsesError{
+			Message: message,
+			Type:    responseErrorTypeInvalidRequest,
+		},
+	})
+}
+
+func (p *responsesProvider) handleStreamResponse(ctx *gin.Context, response responsesResponse, responseText string) {
+	utils.SetEventStreamHeaders(ctx)
+	dataChan := make(chan streamEvent)
+	stopChan := make(chan bool, 1)
+
+	go func() {
+		sequenceNumber := 0
+		send := func(event responsesStreamEvent) {
+			event.SequenceNumber = sequenceNumber
+			sequenceNumber++
+			jsonStr, _ := json.Marshal(event)
+			dataChan <- streamEvent{Event: event.Type, Data: fmt.Sprintf("data: %s", jsonStr)}
+		}
+
+		pending := p.createPendingResponse(response, responseStatusInProgress)
+		send(responsesStreamEvent{Type: responseEventCreated, Response: &pending})
+		send(responsesStreamEvent{Type: responseEventInProgress, Response: &pending})
+
+		item := response.Output[0]
+		pendingItem := item
+		pendingItem.Status = responseStatusInProgress
+		pendingItem.Content = []responsesContent{}
+		send(responsesStreamEvent{Type: responseEventOutputItemAdded, OutputIndex: ptr(0), Item: &pendingItem})
+
+		part := item.Content[0]
+		send(responsesStreamEvent{
+			Type:         responseEventContentPartAdded,
+			ItemId:       item.Id,
+			OutputIndex:  ptr(0),
+			ContentIndex: ptr(0),
+			Part:         &responsesContent{Type: part.Type, Text: "", Annotations: []any{}},
+		})
+		for _, s := range responseText {
+			send(responsesStreamEvent{
+				Type:         responseEventOutputTextDelta,
+				ItemId:       item.Id,
+				OutputIndex:  ptr(0),
+				ContentIndex: ptr(0),
+				Delta:        ptr(string(s)),
+			})
+
+			// Simulate response delay
+			time.Sleep(responseMockDelayPerRune)
+		}
+		send(responsesStreamEvent{
+			Type:         responseEventOutputTextDone,
+			ItemId:       item.Id,
+			OutputIndex:  ptr(0),
+			ContentIndex: ptr(0),
+			Text:         ptr(part.Text),
+		})
+		send(responsesStreamEvent{
+			Type:         responseEventContentPartDone,
+			ItemId:       item.Id,
+			OutputIndex:  ptr(0),
+			ContentIndex: ptr(0),
+			Part:         &part,
+		})
+		send(responsesStreamEvent{Type: responseEventOutputItemDone, OutputIndex: ptr(0), Item: &item})
+		send(responsesStreamEvent{Type: responseEventCompleted, Response: &response})
+		stopChan <- true
+	}()
+
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case event := <-dataChan:
+			ctx.Render(-1, event)
+			return true
+		case <-stopChan:
+			return false
+		}
+	})
+}
+
+func (p *responsesProvider) createResponse(request responsesRequest, store bool, responseText string, inputTokens int) responsesResponse {
+	outputTokens := completionMockUsage.CompletionTokens
+	metadata := request.Metadata
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	tools := request.Tools
+	if tools == nil {
+		tools = []any{}
+	}
+	toolChoice := request.ToolChoice
+	if toolChoice == nil {
+		toolChoice = responseToolChoiceAuto
+	}
+	text := request.Text
+	if text == nil {
+		text = map[string]any{"format": map[string]string{"type": responseTextFormatTypeText}}
+	}
+	return responsesResponse{
+		Id:              provider.RandomToken(responseIdPrefix),
+		Object:          objectResponse,
+		CreatedAt:       completionMockCreated,
+		Status:          responseStatusCompleted,
+		Background:      request.Background,
+		Instructions:    request.Instructions,
+		MaxOutputTokens: request.MaxOutputTokens,
+		Model:           request.Model,
+		Output: []responsesOutputItem{
+			{
+				Type:   responseItemTypeMessage,
+				Id:     provider.RandomToken(messageIdPrefix),
+				Status: responseStatusCompleted,
+				Role:   roleAssistant,
+				Content: []responsesContent{
+					{Type: responseContentTypeOutputText, Text: responseText, Annotations: []any{}},
+				},
+			},
+		},
+		ParallelToolCalls:  request.ParallelToolCalls == nil || *request.ParallelToolCalls,
+		PreviousResponseId: request.PreviousResponseId,
+		Store:              store,
+		Temperature:        request.Temperature,
+		Text:               text,
+		ToolChoice:         toolChoice,
+		Tools:              tools,
+		TopP:               request.TopP,
+		Usage: &responsesUsage{
+			InputTokens:         inputTokens,
+			InputTokensDetails:  responsesInputTokensDetails{},
+			OutputTokens:        outputTokens,
+			OutputTokensDetails: responsesOutputTokensDetails{},
+			TotalTokens:         inputTokens + outputTokens,
+		},
+		User:     request.User,
+		Metadata: metadata,
+	}
+}
+
+// createPendingResponse returns a copy of the completed response which has no output yet.
+func (p *responsesProvider) createPendingResponse(response responsesResponse, status string) responsesResponse {
+	response.Status = status
+	response.Output = []responsesOutputItem{}
+	response.Usage = nil
+	return response
+}
+
+// responseStore keeps the stored responses in memory. A background response is in progress until completesAt.
+type responseStore struct {
+	mutex     sync.Mutex
+	responses map[string]*storedResponse
+}
+
+type storedResponse struct {
+	response    responsesResponse
+	background  bool
+	completesAt time.Time
+	cancelled   bool
+}
+
+func newResponseStore() *responseStore {
+	return &responseStore{responses: map[string]*storedResponse{}}
+}
+
+func (s *responseStore) put(response responsesResponse, background bool, completesAt time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.responses[response.Id] = &storedResponse{
+		response:    response,
+		background:  background,
+		completesAt: completesAt,
+	}
+}
+
+// get returns the current state of the response.
+func (s *responseStore) get(id string) (responsesResponse, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	stored, found := s.responses[id]
+	if !found {
+		return responsesResponse{}, false
+	}
+	return stored.current(), true
+}
+
+func (s *responseStore) delete(id string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, found := s.responses[id]; !found {
+		return false
+	}
+	delete(s.responses, id)
+	return true
+}
+
+// cancel cancels the background response if it is still in progress, and returns its current state.
+func (s *responseStore) cancel(id string) (responsesResponse, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	stored, found := s.responses[id]
+	if !found {
+		return responsesResponse{}, false, nil
+	}
+	if !stored.background {
+		return responsesResponse{}, true, errors.New("Cannot cancel a synchronous response.")
+	}
+	if stored.current().Status == responseStatusCompleted {
+		return responsesResponse{}, true, errors.New("Cannot cancel a completed response.")
+	}
+	stored.cancelled = true
+	return stored.current(), true, nil
+}
+
+func (r *storedResponse) current() responsesResponse {
+	if !r.background || (!r.cancelled && !time.Now().Before(r.completesAt)) {
+		return r.response
+	}
+	response := r.response
+	response.Output = []responsesOutputItem{}
+	response.Usage = nil
+	if r.cancelled {
+		response.Status = responseStatusCancelled
+	} else {
+		response.Status = responseStatusInProgress
+	}
+	return response
+}
+
+type responsesRequest struct {
+	Model              string            `json:"model" validate:"required"`
+	Input              any               `json:"input" validate:"required"`
+	Instructions       string            `json:"instructions,omitempty"`
+	PreviousResponseId string            `json:"previous_response_id,omitempty"`
+	Store              *bool             `json:"store,omitempty"`
+	Stream             bool              `json:"stream,omitempty"`
+	Background         bool              `json:"background,omitempty"`
+	MaxOutputTokens    *int              `json:"max_output_tokens,omitempty"`
+	Temperature        *float64          `json:"temperature,omitempty"`
+	TopP               *float64          `json:"top_p,omitempty"`
+	Tools              []any             `json:"tools,omitempty"`
+	ToolChoice         any               `json:"tool_choice,omitempty"`
+	ParallelToolCalls  *bool             `json:"parallel_tool_calls,omitempty"`
+	Text               any               `json:"text,omitempty"`
+	Reasoning          any               `json:"reasoning,omitempty"`
+	Metadata           map[string]string `json:"metadata,omitempty"`
+	User               string            `json:"user,omitempty"`
+}
+
+// inputText returns the text of the input, which is either a string or a list of items whose last one is used.
+// The content of a message item is either a string or a list of content parts like input_text.
+func (r *responsesRequest) inputText() string {
+	if input, ok := r.Input.(string); ok {
+		return input
+	}
+	items, ok := r.Input.([]any)
+	if !ok || len(items) == 0 {
+		return ""
+	}
+	item, ok := items[len(items)-1].(map[string]any)
+	if !ok {
+		return ""
+	}
+	// The output of a function_call_output item
+	if output, ok := item["output"].(string); ok {
+		return output
+	}
+	if content, ok := item["content"].(string); ok {
+		return content
+	}
+	parts, _ := item["content"].([]any)
+	var texts []string
+	for _, part := range parts {
+		if partMap, ok := part.(map[string]any); ok {
+			if text, ok := partMap["text"].(string); ok {
+				texts = append(texts, text)
+			}
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+type responsesResponse struct {
+	Id                 string                `json:"id"`
+	Object             string                `json:"object"`
+	CreatedAt          int64                 `json:"created_at"`
+	Status             string                `json:"status"`
+	Background         bool                  `json:"background"`
+	Error              any                   `json:"error"`
+	IncompleteDetails  any                   `json:"incomplete_details"`
+	Instructions       string                `json:"instructions,omitempty"`
+	MaxOutputTokens    *int                  `json:"max_output_tokens"`
+	Model              string                `json:"model"`
+	Output             []responsesOutputItem `json:"output"`
+	ParallelToolCalls  bool                  `json:"parallel_tool_calls"`
+	PreviousResponseId string                `json:"previous_response_id,omitempty"`
+	Store              bool                  `json:"store"`
+	Temperature        *float64              `json:"temperature"`
+	Text               any                   `json:"text"`
+	ToolChoice         any                   `json:"tool_choice"`
+	Tools              []any                 `json:"tools"`
+	TopP               *float64              `json:"top_p"`
+	Usage              *responsesUsage       `json:"usage"`
+	User               string                `json:"user,omitempty"`
+	Metadata           map[string]string     `json:"metadata"`
+}
+
+type responsesOutputItem struct {
+	Type    string             `json:"type"`
+	Id      string             `json:"id"`
+	Status  string             `json:"status"`
+	Role    string             `json:"role"`
+	Content []responsesContent `json:"content"`
+}
+
+type responsesContent struct {
+	Type        string `json:"type"`
+	Text        string `json:"text"`
+	Annotations []any  `json:"annotations"`
+}
+
+type responsesUsage struct {
+	InputTokens         int                          `json:"input_tokens"`
+	InputTokensDetails  responsesInputTokensDetails  `json:"input_tokens_details"`
+	OutputTokens        int                          `json:"output_tokens"`
+	OutputTokensDetails responsesOutputTokensDetails `json:"output_tokens_details"`
+	TotalTokens         int                          `json:"total_tokens"`
+}
+
+type responsesInputTokensDetails struct {
+	CachedTokens int `json:"cached_tokens"`
+}
+
+type responsesOutputTokensDetails struct {
+	ReasoningTokens int `json:"reasoning_tokens"`
+}
+
+// responsesStreamEvent is the payload of every streaming event, of which only the fields relevant to the type are set.
+type responsesStreamEvent struct {
+	Type           string               `json:"type"`
+	SequenceNumber int                  `json:"sequence_number"`
+	Response       *responsesResponse   `json:"response,omitempty"`
+	OutputIndex    *int                 `json:"output_index,omitempty"`
+	ContentIndex   *int                 `json:"content_index,omitempty"`
+	ItemId         string               `json:"item_id,omitempty"`
+	Item           *responsesOutputItem `json:"item,omitempty"`
+	Part           *responsesContent    `json:"part,omitempty"`
+	Delta          *string              `json:"delta,omitempty"`
+	Text           *string              `json:"text,omitempty"`
+}
+
+type responsesDeleteResponse struct {
+	Id      string `json:"id"`
+	Object  string `json:"object"`
+	Deleted bool   `json:"deleted"`
+}
+
+type responsesErrorResponse struct {
+	Error responsesError `json:"error"`
+}
+
+type responsesError struct {
+	Message string  `json:"message"`
+	Type    string  `json:"type"`
+	Param   *string `json:"param"`
+	Code    *string `json:"code"`
+}
@@ -0,0 +1,270 @@
+package chat
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+
+	"llm-mock-server/pkg/log"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	sparkMockSid       = "cht000llm-mock"
+	sparkMaxClockSkew  = 5 * time.Minute
+	sparkHmacAlgorithm = "hmac-sha256"
+	sparkSignedHeaders = "host date request-line"
+
+	// sparkStatusFirst, sparkStatusContinue and sparkStatusLast mark the position of a frame in the response stream.
+	sparkStatusFirst    = 0
+	sparkStatusContinue = 1
+	sparkStatusLast     = 2
+
+	sparkCodeSuccess        = 0
+	sparkCodeInvalidParam   = 10163
+	sparkCodeAppIdMismatch  = 10313
+	sparkMessageSuccess     = "Success"
+	sparkMessageInvalidJson = "request data format error"
+)
+
+var (
+	sparkChatPaths = []string{
+		"/v1.1/chat",
+		"/v2.1/chat",
+		"/v3.1/chat",
+		"/v3.5/chat",
+		"/v4.0/chat",
+		"/chat/pro-128k",
+		"/chat/max-32k",
+	}
+
+	// sparkAuthorizationRegex matches the decoded authorization parameter like
+	// api_key="...", algorithm="hmac-sha256", headers="host date request-line", signature="..."
+	sparkAuthorizationRegex = regexp.MustCompile(`^api_key="([^"]*)", ?algorithm="([^"]*)", ?headers="([^"]*)", ?signature="([^"]*)"$`)
+)
+
+// sparkProvider emulates the iFlytek Spark WebSocket chat API, e.g. wss://spark-api.xf-yun.com/v3.5/chat.
+type sparkProvider struct {
+	appId     string
+	apiKey    string
+	apiSecret string
+}
+
+func (p *sparkProvider) registerRoutes(server *gin.Engine) {
+	for _, path := range sparkChatPaths {
+		server.GET(path, p.handleChat)
+	}
+}
+
+func (p *sparkProvider) handleChat(ctx *gin.Context) {
+	// The handshake is authenticated with the signed query parameters before upgrading to WebSocket
+	if statusCode, message := p.verifyAuthorization(ctx); statusCode != http.StatusOK {
+		ctx.JSON(statusCode, gin.H{"message": message})
+		return
+	}
+
+	server := websocket.Server{
+		// Accept connections without the Origin header
+		Handshake: func(config *websocket.Config, request *http.Request) error {
+			return nil
+		},
+		Handler: p.serveConnection,
+	}
+	server.ServeHTTP(ctx.Writer, ctx.Request)
+}
+
+// verifyAuthorization verifies the authorization query parameter, which is the base64-encoded
+//
+//	api_key="{api_key}", algorithm="hmac-sha256", headers="host date request-line", signature="{signature}"
+//
+// where the signature is the base64-encoded HMAC-SHA256 of "host: {host}\ndate: {date}\nGET {path} HTTP/1.1".
+func (p *sparkProvider) verifyAuthorization(ctx *gin.Context) (int, string) {
+	host := ctx.Query("host")
+	date := ctx.Query("date")
+	authorization, err := base64.StdEncoding.DecodeString(ctx.Query("authorization"))
+	if host == "" || date == "" || err != nil {
+		return http.StatusUnauthorized, "Unauthorized"
+	}
+
+	signTime, err := time.Parse(time.RFC1123, date)
+	if err != nil {
+		return http.StatusForbidden, "HMAC signature cannot be verified, a valid date or x-date header is required for HMAC Authentication"
+	}
+	if skew := time.Since(signTime); skew > sparkMaxClockSkew || skew < -sparkMaxClockSkew {
+		return http.StatusForbidden, "HMAC signature cannot be verified, a valid date or x-date header is required for HMAC Authentication"
+	}
+
+	matches := sparkAuthorizationRegex.FindStringSubmatch(string(authorization))
+	if matches == nil || matches[2] != sparkHmacAlgorithm || matches[3] != sparkSignedHeaders {
+		return http.StatusUnauthorized, "HMAC signature cannot be verified"
+	}
+	if matches[1] != p.apiKey {
+		return http.StatusUnauthorized, "HMAC signature cannot be verified"
+	}
+
+	signatureOrigin := fmt.Sprintf("host: %s\ndate: %s\nGET %s HTTP/1.1", host, date, ctx.Request.URL.Path)
+	mac := hmac.New(sha256.New, []byte(p.apiSecret))
+	mac.Write([]byte(signatureOrigin))
+	signature := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(signature), []byte(matches[4])) {
+		return http.StatusUnauthorized, "HMAC signature does not match"
+	}
+	return http.StatusOK, ""
+}
+
+// serveConnection reads a single request frame and streams the response frames back before closing the connection.
+func (p *sparkProvider) serveConnection(conn *websocket.Conn) {
+	defer conn.Close()
+
+	var chatRequest sparkChatRequest
+	if err := websocket.JSON.Receive(conn, &chatRequest); err != nil {
+		p.sendErrorFrame(conn, sparkCodeInvalidParam, sparkMessageInvalidJson)
+		return
+	}
+	if chatRequest.Header.AppId != p.appId {
+		p.sendErrorFrame(conn, sparkCodeAppIdMismatch, "app_id and api_key do not match")
+		return
+	}
+	messages := chatRequest.Payload.Message.Text
+	if len(messages) == 0 {
+		p.sendErrorFrame(conn, sparkCodeInvalidParam, "payload.message.text is required")
+		return
+	}
+
+	var chunks []string
+	for _, s := range prompt2Response(messages[len(messages)-1].Content) {
+		chunks = append(chunks, string(s))
+	}
+	// Clients wait for the last frame, which is sent with the usage even if there is no text
+	if len(chunks) == 0 {
+		chunks = []string{""}
+	}
+	for i, chunk := range chunks {
+		status := sparkStatusContinue
+		if i == 0 {
+			status = sparkStatusFirst
+		}
+		var usage *sparkUsage
+		if i == len(chunks)-1 {
+			status = sparkStatusLast
+			usage = &sparkUsage{Text: sparkTextUsage{
+				QuestionTokens:   completionMockUsage.PromptTokens,
+				PromptTokens:     completionMockUsage.PromptTokens,
+				CompletionTokens: completionMockUsage.CompletionTokens,
+				TotalTokens:      completionMockUsage.TotalTokens,
+			}}
+		}
+		frame := sparkChatResponse{
+			Header: sparkResponseHeader{
+				Code:    sparkCodeSuccess,
+				Message: sparkMessageSuccess,
+				Sid:     sparkMockSid,
+				Status:  status,
+			},
+			Payload: &sparkResponsePayload{
+				Choices: sparkChoices{
+					Status: status,
+					Seq:    i,
+					Text: []sparkText{
+						{Content: chunk, Role: roleAssistant, Index: 0},
+					},
+				},
+				Usage: usage,
+			},
+		}
+		if err := websocket.JSON.Send(conn, frame); err != nil {
+			log.Errorf("Error sending spark frame: %v", err)
+			return
+		}
+
+		// Simulate response delay
+		time.Sleep(200 * time.Millisecond)
+	}
+}
+
+func (p *sparkProvider) sendErrorFrame(conn *websocket.Conn, code int, message string) {
+	_ = websocket.JSON.Send(conn, sparkChatResponse{
+		Header: sparkResponseHeader{
+			Code:    code,
+			Message: message,
+			Sid:     sparkMockSid,
+			Status:  sparkStatusLast,
+		},
+	})
+}
+
+type sparkChatRequest struct {
+	Header    sparkRequestHeader    `json:"header"`
+	Parameter sparkRequestParameter `json:"parameter"`
+	Payload   sparkRequestPayload   `json:"payload"`
+}
+
+type sparkRequestHeader struct {
+	AppId string `json:"app_id"`
+	Uid   string `json:"uid,omitempty"`
+}
+
+type sparkRequestParameter struct {
+	Chat sparkChatParameter `json:"chat"`
+}
+
+type sparkChatParameter struct {
+	Domain      string  `json:"domain"`
+	Temperature float64 `json:"temperature,omitempty"`
+	MaxTokens   int     `json:"max_tokens,omitempty"`
+	TopK        int     `json:"top_k,omitempty"`
+}
+
+type sparkRequestPayload struct {
+	Message sparkRequestMessage `json:"message"`
+}
+
+type sparkRequestMessage struct {
+	Text []sparkText `json:"text"`
+}
+
+type sparkText struct {
+	Content string `json:"content"`
+	Role    string `json:"role"`
+	Index   int    `json:"index"`
+}
+
+type sparkChatResponse struct {
+	Header  sparkResponseHeader   `json:"header"`
+	Payload *sparkResponsePayload `json:"payload,omitempty"`
+}
+
+type sparkResponseHeader struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Sid     string `json:"sid"`
+	Status  int    `json:"status"`
+}
+
+type sparkResponsePayload struct {
+	Choices sparkChoices `json:"choices"`
+	Usage   *sparkUsage  `json:"usage,omitempty"`
+}
+
+type sparkChoices struct {
+	Status int         `json:"status"`
+	Seq    int         `json:"seq"`
+	Text   []sparkText `json:"text"`
+}
+
+type sparkUsage struct {
+	Text sparkTextUsage `json:"text"`
+}
+
+type sparkTextUsage struct {
+	QuestionTokens   int `json:"question_tokens"`
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
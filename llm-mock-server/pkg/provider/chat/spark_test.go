@@ -0,0 +1,89 @@
+package chat
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	testSparkHost      = "spark-api.xf-yun.com"
+	testSparkPath      = "/v3.5/chat"
+	testSparkApiKey    = "llm-mock-key"
+	testSparkApiSecret = "llm-mock-secret"
+)
+
+// signSparkUrl builds the handshake query the Spark SDKs append to the WebSocket URL.
+func signSparkUrl(path, apiSecret string, signTime time.Time) url.Values {
+	date := signTime.UTC().Format(http.TimeFormat)
+	mac := hmac.New(sha256.New, []byte(apiSecret))
+	mac.Write([]byte(fmt.Sprintf("host: %s\ndate: %s\nGET %s HTTP/1.1", testSparkHost, date, path)))
+	authorization := fmt.Sprintf(`api_key="%s", algorithm="%s", headers="%s", signature="%s"`,
+		testSparkApiKey, sparkHmacAlgorithm, sparkSignedHeaders, base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	return url.Values{
+		"host":          {testSparkHost},
+		"date":          {date},
+		"authorization": {base64.StdEncoding.EncodeToString([]byte(authorization))},
+	}
+}
+
+func TestSparkVerifyAuthorization(t *testing.T) {
+	p := &sparkProvider{apiKey: testSparkApiKey, apiSecret: testSparkApiSecret}
+
+	tests := []struct {
+		name           string
+		path           string
+		query          url.Values
+		wantStatusCode int
+	}{
+		{
+			name:           "valid signature",
+			path:           testSparkPath,
+			query:          signSparkUrl(testSparkPath, testSparkApiSecret, time.Now()),
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:           "tampered request line",
+			path:           "/v4.0/chat",
+			query:          signSparkUrl(testSparkPath, testSparkApiSecret, time.Now()),
+			wantStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:           "wrong secret",
+			path:           testSparkPath,
+			query:          signSparkUrl(testSparkPath, "wrong-secret", time.Now()),
+			wantStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:           "expired date",
+			path:           testSparkPath,
+			query:          signSparkUrl(testSparkPath, testSparkApiSecret, time.Now().Add(-time.Hour)),
+			wantStatusCode: http.StatusForbidden,
+		},
+		{
+			name:           "missing authorization",
+			path:           testSparkPath,
+			query:          url.Values{"host": {testSparkHost}, "date": {time.Now().UTC().Format(http.TimeFormat)}},
+			wantStatusCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+			ctx.Request = httptest.NewRequest(http.MethodGet, tt.path+"?"+tt.query.Encode(), nil)
+
+			if statusCode, message := p.verifyAuthorization(ctx); statusCode != tt.wantStatusCode {
+				t.Errorf("status code = %d (%s), want %d", statusCode, message, tt.wantStatusCode)
+			}
+		})
+	}
+}
@@ -36,18 +36,51 @@ var dataReplacer = strings.NewReplacer(
 	"\r", "\\r")
 
 type streamEvent struct {
-	Event string
-	Id    string
-	Retry uint
-	Data  interface{}
+	Event   string
+	Id      string
+	Retry   uint
+	Comment string
+	Data    interface{}
 }
 
 func encode(writer io.Writer, event streamEvent) error {
 	w := checkWriter(writer)
+	writeId(w, event.Id)
+	writeEvent(w, event.Event)
+	writeComment(w, event.Comment)
 	return writeData(w, event.Data)
 }
 
+func writeId(w stringWriter, id string) {
+	if len(id) > 0 {
+		w.writeString("id: ")
+		w.writeString(id)
+		w.writeString("\n")
+	}
+}
+
+func writeEvent(w stringWriter, event string) {
+	if len(event) > 0 {
+		w.writeString("event: ")
+		w.writeString(event)
+		w.writeString("\n")
+	}
+}
+
+func writeComment(w stringWriter, comment string) {
+	if len(comment) > 0 {
+		w.writeString(":")
+		w.writeString(comment)
+		w.writeString("\n")
+	}
+}
+
 func writeData(w stringWriter, data interface{}) error {
+	// An event without data like "event: ping" is terminated by the blank line alone
+	if data == "" {
+		w.writeString("\n")
+		return nil
+	}
 	dataReplacer.WriteString(w, fmt.Sprint(data))
 	if strings.HasPrefix(data.(string), "data") {
 		w.writeString("\n\n")
@@ -68,3 +101,25 @@ func (r streamEvent) WriteContentType(w http.ResponseWriter) {
 		header["Cache-Control"] = noCache
 	}
 }
+
+var ndjsonContentType = []string{"application/x-ndjson"}
+
+// ndjsonEvent renders a line of newline-delimited JSON, which is used by some providers instead of Server-Sent Events.
+type ndjsonEvent struct {
+	Data string
+}
+
+func (r ndjsonEvent) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	_, err := checkWriter(w).writeString(r.Data + "\n")
+	return err
+}
+
+func (r ndjsonEvent) WriteContentType(w http.ResponseWriter) {
+	header := w.Header()
+	header["Content-Type"] = ndjsonContentType
+
+	if _, exist := header["Cache-Control"]; !exist {
+		header["Cache-Control"] = noCache
+	}
+}
@@ -0,0 +1,294 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"llm-mock-server/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	tgiGeneratePath       = "/generate"
+	tgiGenerateStreamPath = "/generate_stream"
+	tgiInfoPath           = "/info"
+
+	tgiMockModelId         = "meta-llama/Meta-Llama-3-8B-Instruct"
+	tgiDefaultMaxNewTokens = 100
+	tgiMaxStopSequences    = 4
+	// tgiMockLogprob is the log probability of every generated token.
+	tgiMockLogprob = -0.25
+
+	tgiFinishReasonLength       = "length"
+	tgiFinishReasonEosToken     = "eos_token"
+	tgiFinishReasonStopSequence = "stop_sequence"
+
+	tgiErrorTypeValidation = "validation"
+)
+
+// tgiEosToken is the special token ending the generation, which is not included in the generated text.
+var tgiEosToken = tgiToken{Id: 128009, Text: "<|eot_id|>", Logprob: 0, Special: true}
+
+// tgiProvider handles the native API of Hugging Face Text Generation Inference, which is usually self-hosted,
+// so requests are matched by path regardless of the host.
+type tgiProvider struct{}
+
+func (p *tgiProvider) ShouldHandleRequest(ctx *gin.Context) bool {
+	context, _ := getRequestContext(ctx)
+	return context.Path == tgiGeneratePath || context.Path == tgiGenerateStreamPath
+}
+
+func (p *tgiProvider) registerRoutes(server *gin.Engine) {
+	server.GET(tgiInfoPath, p.handleInfo)
+}
+
+func (p *tgiProvider) handleInfo(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, tgiInfo{
+		ModelId:               tgiMockModelId,
+		ModelDtype:            "torch.float16",
+		ModelDeviceType:       "cuda",
+		ModelPipelineTag:      "text-generation",
+		MaxConcurrentRequests: 128,
+		MaxBestOf:             2,
+		MaxStopSequences:      tgiMaxStopSequences,
+		MaxInputTokens:        4095,
+		MaxTotalTokens:        4096,
+		MaxWaitingTokens:      20,
+		ValidationWorkers:     2,
+		MaxClientBatchSize:    4,
+		Router:                "text-generation-router",
+		Version:               "2.4.0",
+	})
+}
+
+func (p *tgiProvider) HandleChatCompletions(ctx *gin.Context) {
+	// Bind request body
+	var generateRequest tgiGenerateRequest
+	if err := ctx.ShouldBindJSON(&generateRequest); err != nil {
+		p.sendErrorResponse(ctx, http.StatusUnprocessableEntity, tgiErrorTypeValidation,
+			fmt.Sprintf("Failed to deserialize the JSON body into the target type: %v", err))
+		return
+	}
+
+	// Validate request body
+	if err := utils.Validate.Struct(generateRequest); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		for _, fieldError := range validationErrors {
+			p.sendErrorResponse(ctx, http.StatusUnprocessableEntity, tgiErrorTypeValidation,
+				fmt.Sprintf("Input validation error: %v", fieldError.Error()))
+			return
+		}
+	}
+	parameters := generateRequest.Parameters
+	if parameters.MaxNewTokens != nil && *parameters.MaxNewTokens <= 0 {
+		p.sendErrorResponse(ctx, http.StatusUnprocessableEntity, tgiErrorTypeValidation,
+			"Input validation error: `max_new_tokens` must be strictly positive")
+		return
+	}
+	if len(parameters.Stop) > tgiMaxStopSequences {
+		p.sendErrorResponse(ctx, http.StatusUnprocessableEntity, tgiErrorTypeValidation,
+			fmt.Sprintf("Input validation error: `stop` supports up to %d stop sequences. Given: %d", tgiMaxStopSequences, len(parameters.Stop)))
+		return
+	}
+
+	generation := p.generate(generateRequest)
+	ctx.Header("x-model-id", tgiMockModelId)
+	ctx.Header("x-prompt-tokens", strconv.Itoa(completionMockUsage.PromptTokens))
+	ctx.Header("x-generated-tokens", strconv.Itoa(len(generation.tokens)))
+
+	context, _ := getRequestContext(ctx)
+	if context.Path == tgiGenerateStreamPath || generateRequest.Stream {
+		p.handleStreamResponse(ctx, generation)
+	} else {
+		p.handleNonStreamResponse(ctx, generateRequest, generation)
+	}
+}
+
+func (p *tgiProvider) sendErrorResponse(ctx *gin.Context, statusCode int, errorType, errorMsg string) {
+	ctx.JSON(statusCode, tgiErrorResp{
+		Error:     errorMsg,
+		ErrorType: errorType,
+	})
+}
+
+// tgiGeneration is the result of the mock generation, in which each character is a token.
+type tgiGeneration struct {
+	text         string
+	tokens       []tgiToken
+	finishReason string
+}
+
+// generate generates the tokens of the response until the stop sequence, the max new tokens or the EOS token.
+func (p *tgiProvider) generate(generateRequest tgiGenerateRequest) tgiGeneration {
+	parameters := generateRequest.Parameters
+	maxNewTokens := tgiDefaultMaxNewTokens
+	if parameters.MaxNewTokens != nil {
+		maxNewTokens = *parameters.MaxNewTokens
+	}
+
+	generation := tgiGeneration{finishReason: tgiFinishReasonEosToken}
+	for _, s := range prompt2Response(generateRequest.Inputs) {
+		if len(generation.tokens) == maxNewTokens {
+			generation.finishReason = tgiFinishReasonLength
+			break
+		}
+		generation.text += string(s)
+		generation.tokens = append(generation.tokens, tgiToken{Id: int(s), Text: string(s), Logprob: tgiMockLogprob})
+		// The stop sequence is included in the generated text
+		if p.endsWithStopSequence(generation.text, parameters.Stop) {
+			generation.finishReason = tgiFinishReasonStopSequence
+			break
+		}
+	}
+	// The EOS token counts towards the max new tokens, so it is only generated if there is room left
+	if generation.finishReason == tgiFinishReasonEosToken {
+		if len(generation.tokens) < maxNewTokens {
+			generation.tokens = append(generation.tokens, tgiEosToken)
+		} else {
+			generation.finishReason = tgiFinishReasonLength
+		}
+	}
+	if parameters.ReturnFullText {
+		generation.text = generateRequest.Inputs + generation.text
+	}
+	return generation
+}
+
+func (p *tgiProvider) endsWithStopSequence(text string, stop []string) bool {
+	for _, sequence := range stop {
+		if sequence != "" && strings.HasSuffix(text, sequence) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *tgiProvider) handleStreamResponse(ctx *gin.Context, generation tgiGeneration) {
+	utils.SetEventStreamHeaders(ctx)
+	dataChan := make(chan string)
+	stopChan := make(chan bool, 1)
+
+	go func() {
+		for i, token := range generation.tokens {
+			streamResponse := tgiStreamResponse{
+				Index: i + 1,
+				Token: token,
+			}
+			// The last event carries the whole generated text and the details
+			if i == len(generation.tokens)-1 {
+				streamResponse.GeneratedText = ptr(generation.text)
+				streamResponse.Details = &tgiDetails{
+					FinishReason:    generation.finishReason,
+					GeneratedTokens: len(generation.tokens),
+				}
+			}
+			jsonStr, _ := json.Marshal(streamResponse)
+			dataChan <- string(jsonStr)
+
+			// Simulate response delay
+			time.Sleep(200 * time.Millisecond)
+		}
+		stopChan <- true
+	}()
+
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case data := <-dataChan:
+			ctx.Render(-1, streamEvent{Data: "data:" + data})
+			return true
+		case <-stopChan:
+			return false
+		}
+	})
+}
+
+func (p *tgiProvider) handleNonStreamResponse(ctx *gin.Context, generateRequest tgiGenerateRequest, generation tgiGeneration) {
+	response := tgiGenerateResponse{GeneratedText: generation.text}
+	if generateRequest.Parameters.Details {
+		response.Details = &tgiDetails{
+			FinishReason:    generation.finishReason,
+			GeneratedTokens: len(generation.tokens),
+			Seed:            generateRequest.Parameters.Seed,
+			Prefill:         []tgiToken{},
+			Tokens:          generation.tokens,
+		}
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+type tgiGenerateRequest struct {
+	Inputs     string                `json:"inputs" validate:"required"`
+	Parameters tgiGenerateParameters `json:"parameters,omitempty"`
+	Stream     bool                  `json:"stream,omitempty"`
+}
+
+type tgiGenerateParameters struct {
+	MaxNewTokens      *int     `json:"max_new_tokens,omitempty"`
+	Details           bool     `json:"details,omitempty"`
+	Stop              []string `json:"stop,omitempty"`
+	ReturnFullText    bool     `json:"return_full_text,omitempty"`
+	DoSample          bool     `json:"do_sample,omitempty"`
+	Temperature       float64  `json:"temperature,omitempty" validate:"omitempty,gt=0"`
+	TopP              float64  `json:"top_p,omitempty" validate:"omitempty,gt=0,lt=1"`
+	TopK              int      `json:"top_k,omitempty" validate:"omitempty,gt=0"`
+	RepetitionPenalty float64  `json:"repetition_penalty,omitempty" validate:"omitempty,gt=0"`
+	Seed              *int64   `json:"seed,omitempty"`
+}
+
+type tgiGenerateResponse struct {
+	GeneratedText string      `json:"generated_text"`
+	Details       *tgiDetails `json:"details,omitempty"`
+}
+
+type tgiStreamResponse struct {
+	Index         int         `json:"index"`
+	Token         tgiToken    `json:"token"`
+	GeneratedText *string     `json:"generated_text"`
+	Details       *tgiDetails `json:"details"`
+}
+
+type tgiDetails struct {
+	FinishReason    string     `json:"finish_reason"`
+	GeneratedTokens int        `json:"generated_tokens"`
+	Seed            *int64     `json:"seed"`
+	Prefill         []tgiToken `json:"prefill,omitempty"`
+	Tokens          []tgiToken `json:"tokens,omitempty"`
+}
+
+type tgiToken struct {
+	Id      int     `json:"id"`
+	Text    string  `json:"text"`
+	Logprob float64 `json:"logprob"`
+	Special bool    `json:"special"`
+}
+
+type tgiInfo struct {
+	ModelId               string  `json:"model_id"`
+	ModelSha              *string `json:"model_sha"`
+	ModelDtype            string  `json:"model_dtype"`
+	ModelDeviceType       string  `json:"model_device_type"`
+	ModelPipelineTag      string  `json:"model_pipeline_tag"`
+	MaxConcurrentRequests int     `json:"max_concurrent_requests"`
+	MaxBestOf             int     `json:"max_best_of"`
+	MaxStopSequences      int     `json:"max_stop_sequences"`
+	MaxInputTokens        int     `json:"max_input_tokens"`
+	MaxTotalTokens        int     `json:"max_total_tokens"`
+	MaxWaitingTokens      int     `json:"max_waiting_tokens"`
+	MaxBatchSize          *int    `json:"max_batch_size"`
+	ValidationWorkers     int     `json:"validation_workers"`
+	MaxClientBatchSize    int     `json:"max_client_batch_size"`
+	Router                string  `json:"router"`
+	Version               string  `json:"version"`
+}
+
+type tgiErrorResp struct {
+	Error     string `json:"error"`
+	ErrorType string `json:"error_type"`
+}
@@ -0,0 +1,232 @@
+package chat
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"llm-mock-server/pkg/provider"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	vertexPublisherGoogle    = "google"
+	vertexPublisherAnthropic = "anthropic"
+
+	vertexActionRawPredict       = "rawPredict"
+	vertexActionStreamRawPredict = "streamRawPredict"
+	vertexAnthropicVersion       = "vertex-2023-10-16"
+
+	vertexOAuthDomain          = "oauth2.googleapis.com"
+	vertexOAuthTokenPath       = "/token"
+	vertexGrantTypeJwtBearer   = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+	vertexAccessTokenPrefix    = "ya29.llm-mock."
+	vertexTokenTypeBearer      = "Bearer"
+	vertexJwtAlgorithm         = "RS256"
+	vertexMaxAssertionLifetime = time.Hour
+	vertexAssertionClockSkew   = 5 * time.Minute
+)
+
+// vertexApiVersions lists the API versions whose model paths are supported.
+var vertexApiVersions = []string{"/v1/", "/v1beta1/"}
+
+type vertexProvider struct {
+	auth           *provider.GoogleAuth
+	accessTokenTTL time.Duration
+	gemini         *geminiProvider
+	claude         *claudeProvider
+}
+
+// newVertexProvider creates the provider issuing and accepting the access tokens of the service account of auth.
+func newVertexProvider(auth *provider.GoogleAuth, accessTokenTTL time.Duration) *vertexProvider {
+	return &vertexProvider{
+		auth:           auth,
+		accessTokenTTL: accessTokenTTL,
+		gemini:         &geminiProvider{auth: auth},
+		claude:         &claudeProvider{},
+	}
+}
+
+func (p *vertexProvider) ShouldHandleRequest(ctx *gin.Context) bool {
+	context, _ := getRequestContext(ctx)
+	if !strings.HasSuffix(context.Host, provider.VertexDomainSuffix) {
+		return false
+	}
+	_, found := parseVertexModelPath(context.Path)
+	return found
+}
+
+// registerRoutes registers the token endpoint, and the chat actions of the model routes, which are shared with
+// the embedding actions.
+func (p *vertexProvider) registerRoutes(server *gin.Engine) {
+	server.POST(vertexOAuthTokenPath, p.handleOAuthToken)
+	provider.HandleModelActions(server, provider.VertexModelRoutes,
+		[]string{geminiActionGenerateContent, geminiActionStreamGenerateContent, vertexActionRawPredict, vertexActionStreamRawPredict},
+		handleChatCompletions)
+}
+
+// handleOAuthToken issues an access token in exchange for a JWT assertion signed by the service account.
+func (p *vertexProvider) handleOAuthToken(ctx *gin.Context) {
+	// The bare token path would match the token endpoints of other services
+	if ctx.Request.Host != vertexOAuthDomain {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+		return
+	}
+	if ctx.PostForm("grant_type") != vertexGrantTypeJwtBearer {
+		p.sendOAuthErrorResponse(ctx, "unsupported_grant_type", "Invalid grant_type: "+ctx.PostForm("grant_type"))
+		return
+	}
+	if err := p.verifyAssertion(ctx.PostForm("assertion")); err != nil {
+		p.sendOAuthErrorResponse(ctx, err.Error, err.ErrorDescription)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, vertexOAuthTokenResp{
+		AccessToken: p.auth.AccessTokens.Issue(vertexAccessTokenPrefix, p.accessTokenTTL),
+		ExpiresIn:   int64(p.accessTokenTTL.Seconds()),
+		TokenType:   vertexTokenTypeBearer,
+	})
+}
+
+// verifyAssertion verifies the RS256 signature and the claims of the JWT assertion.
+func (p *vertexProvider) verifyAssertion(assertion string) *vertexOAuthErrorResp {
+	jwt, err := parseJwt(assertion)
+	if err != nil {
+		return &vertexOAuthErrorResp{Error: "invalid_request", ErrorDescription: "Invalid JWT: " + err.Error()}
+	}
+	if jwt.Header["alg"] != vertexJwtAlgorithm {
+		return &vertexOAuthErrorResp{Error: "invalid_grant", ErrorDescription: "Invalid JWT Signature."}
+	}
+	if kid, ok := jwt.Header["kid"]; ok && kid != p.auth.ServiceAccount.PrivateKeyId {
+		return &vertexOAuthErrorResp{Error: "invalid_grant", ErrorDescription: "Invalid JWT Signature."}
+	}
+	digest := sha256.Sum256([]byte(jwt.SigningInput))
+	if rsa.VerifyPKCS1v15(p.auth.ServiceAccount.PublicKey, crypto.SHA256, digest[:], jwt.Signature) != nil {
+		return &vertexOAuthErrorResp{Error: "invalid_grant", ErrorDescription: "Invalid JWT Signature."}
+	}
+	if jwt.Claims["iss"] != p.auth.ServiceAccount.ClientEmail {
+		return &vertexOAuthErrorResp{Error: "invalid_grant", ErrorDescription: "Invalid grant: account not found"}
+	}
+	if jwt.Claims["aud"] != p.auth.ServiceAccount.TokenUri {
+		return &vertexOAuthErrorResp{Error: "invalid_grant",
+			ErrorDescription: "Invalid JWT: Failed audience check. The right audience is " + p.auth.ServiceAccount.TokenUri}
+	}
+	if scope, _ := jwt.Claims["scope"].(string); scope == "" {
+		return &vertexOAuthErrorResp{Error: "invalid_scope", ErrorDescription: "Empty or missing scope not allowed."}
+	}
+
+	// The assertion must be short-lived and valid now
+	iat, iatOk := jwt.numericClaim("iat")
+	exp, expOk := jwt.numericClaim("exp")
+	now := time.Now()
+	issuedAt, expiresAt := time.Unix(iat, 0), time.Unix(exp, 0)
+	if !iatOk || !expOk || expiresAt.Sub(issuedAt) > vertexMaxAssertionLifetime ||
+		issuedAt.After(now.Add(vertexAssertionClockSkew)) || expiresAt.Before(now.Add(-vertexAssertionClockSkew)) {
+		return &vertexOAuthErrorResp{Error: "invalid_grant",
+			ErrorDescription: "Invalid JWT: Token must be a short-lived token (60 minutes) and in a reasonable timeframe. Check your iat and exp values in the JWT claim."}
+	}
+	return nil
+}
+
+func (p *vertexProvider) sendOAuthErrorResponse(ctx *gin.Context, errorCode, errorDescription string) {
+	ctx.JSON(http.StatusBadRequest, vertexOAuthErrorResp{
+		Error:            errorCode,
+		ErrorDescription: errorDescription,
+	})
+}
+
+func (p *vertexProvider) HandleChatCompletions(ctx *gin.Context) {
+	// Validate access token, which is only valid for the project of the service account
+	context, _ := getRequestContext(ctx)
+	modelPath, _ := parseVertexModelPath(context.Path)
+	if !p.auth.AuthenticateVertex(ctx, modelPath.Project, modelPath.Resource()) {
+		return
+	}
+
+	switch {
+	case modelPath.Publisher == vertexPublisherGoogle &&
+		(modelPath.Action == geminiActionGenerateContent || modelPath.Action == geminiActionStreamGenerateContent):
+		p.gemini.handleGenerateContent(ctx, modelPath.Model, modelPath.Action)
+	case modelPath.Publisher == vertexPublisherAnthropic &&
+		(modelPath.Action == vertexActionRawPredict || modelPath.Action == vertexActionStreamRawPredict):
+		p.handleRawPredict(ctx, modelPath)
+	default:
+		p.gemini.sendErrorResponse(ctx, http.StatusNotFound, geminiStatusNotFound,
+			fmt.Sprintf("Publisher Model `%s` not found.", modelPath.Resource()))
+	}
+}
+
+// handleRawPredict handles the Claude Messages request, whose model is given in the path and whose version is given
+// in the body.
+func (p *vertexProvider) handleRawPredict(ctx *gin.Context, modelPath vertexModelPath) {
+	var chatRequest claudeChatMessageRequest
+	if err := ctx.ShouldBindJSON(&chatRequest); err != nil {
+		p.claude.sendErrorResponse(ctx, http.StatusBadRequest, claudeErrorTypeInvalidRequest, err.Error())
+		return
+	}
+	if chatRequest.AnthropicVersion != vertexAnthropicVersion {
+		p.claude.sendErrorResponse(ctx, http.StatusBadRequest, claudeErrorTypeInvalidRequest,
+			fmt.Sprintf("anthropic_version: must be %q", vertexAnthropicVersion))
+		return
+	}
+	chatRequest.Model = modelPath.Model
+	chatRequest.Stream = chatRequest.Stream || modelPath.Action == vertexActionStreamRawPredict
+	p.claude.handleMessages(ctx, chatRequest)
+}
+
+// vertexModelPath is the parsed path of a request to a publisher model.
+type vertexModelPath struct {
+	Project   string
+	Location  string
+	Publisher string
+	Model     string
+	Action    string
+}
+
+// Resource returns the resource name of the publisher model.
+func (m vertexModelPath) Resource() string {
+	return fmt.Sprintf("projects/%s/locations/%s/publishers/%s/models/%s", m.Project, m.Location, m.Publisher, m.Model)
+}
+
+// parseVertexModelPath parses a path like
+// "/v1/projects/{project}/locations/{location}/publishers/{publisher}/models/{model}:{action}".
+func parseVertexModelPath(path string) (vertexModelPath, bool) {
+	for _, version := range vertexApiVersions {
+		if !strings.HasPrefix(path, version) {
+			continue
+		}
+		segments := strings.Split(strings.TrimPrefix(path, version), "/")
+		if len(segments) != 8 || segments[0] != "projects" || segments[2] != "locations" ||
+			segments[4] != "publishers" || segments[6] != "models" {
+			return vertexModelPath{}, false
+		}
+		model, action, found := strings.Cut(segments[7], ":")
+		if !found {
+			return vertexModelPath{}, false
+		}
+		return vertexModelPath{
+			Project:   segments[1],
+			Location:  segments[3],
+			Publisher: segments[5],
+			Model:     model,
+			Action:    action,
+		}, true
+	}
+	return vertexModelPath{}, false
+}
+
+type vertexOAuthTokenResp struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+	TokenType   string `json:"token_type"`
+}
+
+type vertexOAuthErrorResp struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
@@ -0,0 +1,171 @@
+package chat
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"llm-mock-server/pkg/provider"
+
+	"github.com/gin-gonic/gin"
+)
+
+// testVertexServiceAccountKeyFile is the mock service account key, whose private key signs the test assertions.
+const testVertexServiceAccountKeyFile = "../vertex_service_account.json"
+
+func loadVertexPrivateKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := os.ReadFile(testVertexServiceAccountKeyFile)
+	if err != nil {
+		t.Fatalf("failed to read service account key: %v", err)
+	}
+	var serviceAccount provider.GoogleServiceAccount
+	if err := json.Unmarshal(key, &serviceAccount); err != nil {
+		t.Fatalf("failed to parse service account key: %v", err)
+	}
+	block, _ := pem.Decode([]byte(serviceAccount.PrivateKey))
+	privateKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		t.Fatalf("failed to parse private key: %v", err)
+	}
+	return privateKey.(*rsa.PrivateKey)
+}
+
+// signVertexAssertion builds the JWT assertion the Google auth libraries exchange for an access token.
+func signVertexAssertion(t *testing.T, privateKey *rsa.PrivateKey, serviceAccount *provider.GoogleServiceAccount,
+	issuedAt time.Time) string {
+	header := map[string]any{"alg": vertexJwtAlgorithm, "typ": "JWT", "kid": serviceAccount.PrivateKeyId}
+	claims := map[string]any{
+		"iss":   serviceAccount.ClientEmail,
+		"aud":   serviceAccount.TokenUri,
+		"scope": "https://www.googleapis.com/auth/cloud-platform",
+		"iat":   issuedAt.Unix(),
+		"exp":   issuedAt.Add(time.Hour).Unix(),
+	}
+	return signJwt(t, header, claims, func(signingInput []byte) []byte {
+		digest := sha256.Sum256(signingInput)
+		signature, err := rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatalf("failed to sign assertion: %v", err)
+		}
+		return signature
+	})
+}
+
+func TestVertexVerifyAssertion(t *testing.T) {
+	p := newVertexProvider(provider.NewGoogleAuth(testVertexServiceAccountKeyFile), time.Hour)
+	privateKey := loadVertexPrivateKey(t)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	serviceAccount := p.auth.ServiceAccount
+
+	tests := []struct {
+		name        string
+		assertion   string
+		wantError   string
+		description string
+	}{
+		{
+			name:      "valid assertion",
+			assertion: signVertexAssertion(t, privateKey, serviceAccount, time.Now()),
+		},
+		{
+			name:        "tampered signature",
+			assertion:   tamperJwtSignature(signVertexAssertion(t, privateKey, serviceAccount, time.Now())),
+			wantError:   "invalid_grant",
+			description: "Invalid JWT Signature.",
+		},
+		{
+			name:        "signed by another key",
+			assertion:   signVertexAssertion(t, otherKey, serviceAccount, time.Now()),
+			wantError:   "invalid_grant",
+			description: "Invalid JWT Signature.",
+		},
+		{
+			name:        "expired assertion",
+			assertion:   signVertexAssertion(t, privateKey, serviceAccount, time.Now().Add(-2*time.Hour)),
+			wantError:   "invalid_grant",
+			description: "Token must be a short-lived token",
+		},
+		{
+			name:      "malformed assertion",
+			assertion: "not-a-jwt",
+			wantError: "invalid_request",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := p.verifyAssertion(tt.assertion)
+			if tt.wantError == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %+v", err)
+				}
+				return
+			}
+			if err == nil || err.Error != tt.wantError || !strings.Contains(err.ErrorDescription, tt.description) {
+				t.Errorf("error = %+v, want %s: %s", err, tt.wantError, tt.description)
+			}
+		})
+	}
+}
+
+func TestVertexAccessToken(t *testing.T) {
+	p := newVertexProvider(provider.NewGoogleAuth(testVertexServiceAccountKeyFile), time.Hour)
+	assertion := signVertexAssertion(t, loadVertexPrivateKey(t), p.auth.ServiceAccount, time.Now())
+
+	tests := []struct {
+		name           string
+		host           string
+		wantStatusCode int
+	}{
+		{name: "oauth host", host: vertexOAuthDomain, wantStatusCode: http.StatusOK},
+		{name: "other host", host: "api.openai.com", wantStatusCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{"grant_type": {vertexGrantTypeJwtBearer}, "assertion": {assertion}}
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = httptest.NewRequest(http.MethodPost, vertexOAuthTokenPath, strings.NewReader(form.Encode()))
+			ctx.Request.Host = tt.host
+			ctx.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			p.handleOAuthToken(ctx)
+			if recorder.Code != tt.wantStatusCode {
+				t.Fatalf("status code = %d (%s), want %d", recorder.Code, recorder.Body, tt.wantStatusCode)
+			}
+			if tt.wantStatusCode != http.StatusOK {
+				return
+			}
+
+			// The issued token authenticates the requests to the project of the service account only
+			var tokenResp vertexOAuthTokenResp
+			if err := json.Unmarshal(recorder.Body.Bytes(), &tokenResp); err != nil {
+				t.Fatalf("invalid token response: %v", err)
+			}
+			for project, authenticated := range map[string]bool{p.auth.ServiceAccount.ProjectId: true, "other": false} {
+				ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+				ctx.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+				ctx.Request.Header.Set("Authorization", "Bearer "+tokenResp.AccessToken)
+				if p.auth.AuthenticateVertex(ctx, project, "projects/"+project) != authenticated {
+					t.Errorf("authenticated = %v for project %s, want %v", !authenticated, project, authenticated)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package chat
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"llm-mock-server/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	zhipuDomain             = "open.bigmodel.cn"
+	zhipuChatCompletionPath = "/api/paas/v4/chat/completions"
+
+	zhipuErrorCodeAuthFailed       = "1000"
+	zhipuErrorCodeMissingAuth      = "1001"
+	zhipuErrorCodeInvalidToken     = "1002"
+	zhipuErrorCodeTokenExpired     = "1003"
+	zhipuErrorCodeTokenVerifyError = "1004"
+	zhipuErrorCodeInvalidParam     = "1214"
+)
+
+type zhipuProvider struct {
+	// apiKey is in the form of "{id}.{secret}"
+	apiKey string
+	openAi *openAiProvider
+}
+
+func (p *zhipuProvider) ShouldHandleRequest(ctx *gin.Context) bool {
+	context, _ := getRequestContext(ctx)
+	return context.Host == zhipuDomain && context.Path == zhipuChatCompletionPath
+}
+
+func (p *zhipuProvider) HandleChatCompletions(ctx *gin.Context) {
+	// Validate Authorization header
+	authHeader := ctx.GetHeader("Authorization")
+	if authHeader == "" {
+		p.sendErrorResponse(ctx, http.StatusUnauthorized, zhipuErrorCodeMissingAuth,
+			"Header中未收到Authorization参数，无法进行身份验证。")
+		return
+	}
+	if code, message := p.verifyToken(strings.TrimPrefix(authHeader, "Bearer ")); code != "" {
+		p.sendErrorResponse(ctx, http.StatusUnauthorized, code, message)
+		return
+	}
+
+	// Bind request body
+	var chatRequest chatCompletionRequest
+	if err := ctx.ShouldBindJSON(&chatRequest); err != nil {
+		p.sendErrorResponse(ctx, http.StatusBadRequest, zhipuErrorCodeInvalidParam,
+			fmt.Sprintf("请求参数非法：%v", err.Error()))
+		return
+	}
+
+	// Validate request body
+	if err := utils.Validate.Struct(chatRequest); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		for _, fieldError := range validationErrors {
+			p.sendErrorResponse(ctx, http.StatusBadRequest, zhipuErrorCodeInvalidParam,
+				fmt.Sprintf("请求参数非法：%v", fieldError.Error()))
+			return
+		}
+	}
+
+	prompt := ""
+	if chatRequest.Messages[len(chatRequest.Messages)-1].IsStringContent() {
+		prompt = chatRequest.Messages[len(chatRequest.Messages)-1].StringContent()
+	}
+	response := prompt2Response(prompt)
+
+	// The response format is compatible with OpenAI's
+	if chatRequest.Stream {
+		p.openAi.handleStreamResponse(ctx, chatRequest, response)
+	} else {
+		p.openAi.handleNonStreamResponse(ctx, chatRequest, response)
+	}
+}
+
+// verifyToken verifies the token, which is either the API key itself or a JWT built from the API key:
+//
+//	header:  {"alg": "HS256", "sign_type": "SIGN"}
+//	payload: {"api_key": "{id}", "exp": <expiration in ms>, "timestamp": <current time in ms>}
+//
+// and signed with the secret. The error code and message are returned if the verification fails.
+func (p *zhipuProvider) verifyToken(token string) (string, string) {
+	if token == p.apiKey {
+		return "", ""
+	}
+	id, secret, _ := strings.Cut(p.apiKey, ".")
+
+	jwt, err := parseJwt(token)
+	if err != nil {
+		return zhipuErrorCodeInvalidToken, "Authorization Token非法，请确认Authorization Token正确传递。"
+	}
+	if jwt.Header["alg"] != "HS256" {
+		return zhipuErrorCodeInvalidToken, "Authorization Token非法，请确认Authorization Token正确传递。"
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(jwt.SigningInput))
+	if !hmac.Equal(mac.Sum(nil), jwt.Signature) {
+		return zhipuErrorCodeTokenVerifyError, "通过Authorization Token的验证失败。"
+	}
+	if jwt.Claims["api_key"] != id {
+		return zhipuErrorCodeAuthFailed, "身份验证失败。"
+	}
+	if _, ok := jwt.numericClaim("timestamp"); !ok {
+		return zhipuErrorCodeInvalidToken, "Authorization Token非法，请确认Authorization Token正确传递。"
+	}
+	exp, ok := jwt.numericClaim("exp")
+	if !ok {
+		return zhipuErrorCodeInvalidToken, "Authorization Token非法，请确认Authorization Token正确传递。"
+	}
+	if exp < time.Now().UnixMilli() {
+		return zhipuErrorCodeTokenExpired, "Authorization Token已过期，重新生成/获取。"
+	}
+	return "", ""
+}
+
+func (p *zhipuProvider) sendErrorResponse(ctx *gin.Context, statusCode int, errorCode, errorMsg string) {
+	ctx.JSON(statusCode, zhipuErrorResp{
+		Error: zhipuError{
+			Code:    errorCode,
+			Message: errorMsg,
+		},
+	})
+}
+
+type zhipuErrorResp struct {
+	Error zhipuError `json:"error"`
+}
+
+type zhipuError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,81 @@
+package chat
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"testing"
+	"time"
+)
+
+const (
+	testZhipuApiKeyId     = "llm-mock"
+	testZhipuApiKeySecret = "llm-mock-secret"
+)
+
+// signZhipuToken builds the JWT the Zhipu SDKs generate from the API key.
+func signZhipuToken(t *testing.T, id, secret string, exp time.Time) string {
+	header := map[string]any{"alg": "HS256", "sign_type": "SIGN"}
+	claims := map[string]any{
+		"api_key":   id,
+		"exp":       exp.UnixMilli(),
+		"timestamp": time.Now().UnixMilli(),
+	}
+	return signJwt(t, header, claims, func(signingInput []byte) []byte {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(signingInput)
+		return mac.Sum(nil)
+	})
+}
+
+func TestZhipuVerifyToken(t *testing.T) {
+	p := &zhipuProvider{apiKey: testZhipuApiKeyId + "." + testZhipuApiKeySecret}
+	validExp := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name     string
+		token    string
+		wantCode string
+	}{
+		{
+			name:  "api key",
+			token: p.apiKey,
+		},
+		{
+			name:  "valid token",
+			token: signZhipuToken(t, testZhipuApiKeyId, testZhipuApiKeySecret, validExp),
+		},
+		{
+			name:     "tampered signature",
+			token:    tamperJwtSignature(signZhipuToken(t, testZhipuApiKeyId, testZhipuApiKeySecret, validExp)),
+			wantCode: zhipuErrorCodeTokenVerifyError,
+		},
+		{
+			name:     "wrong secret",
+			token:    signZhipuToken(t, testZhipuApiKeyId, "wrong-secret", validExp),
+			wantCode: zhipuErrorCodeTokenVerifyError,
+		},
+		{
+			name:     "wrong api key id",
+			token:    signZhipuToken(t, "other", testZhipuApiKeySecret, validExp),
+			wantCode: zhipuErrorCodeAuthFailed,
+		},
+		{
+			name:     "expired token",
+			token:    signZhipuToken(t, testZhipuApiKeyId, testZhipuApiKeySecret, time.Now().Add(-time.Minute)),
+			wantCode: zhipuErrorCodeTokenExpired,
+		},
+		{
+			name:     "malformed token",
+			token:    "not-a-jwt",
+			wantCode: zhipuErrorCodeInvalidToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code, message := p.verifyToken(tt.token); code != tt.wantCode {
+				t.Errorf("code = %q (%s), want %q", code, message, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package embeddings
+
+import (
+	"net/http"
+
+	"llm-mock-server/pkg/provider"
+	"llm-mock-server/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const azureEmbeddingsPathSuffix = "/embeddings"
+
+// azureProvider serves the Azure OpenAI embeddings, whose request and response are compatible with OpenAI's.
+type azureProvider struct {
+	openAi *openAiProvider
+}
+
+func (p *azureProvider) ShouldHandleRequest(ctx *gin.Context) bool {
+	return provider.IsAzureRequest(ctx, azureEmbeddingsPathSuffix)
+}
+
+func (p *azureProvider) HandleEmbeddings(ctx *gin.Context) {
+	if !provider.ValidateAzureRequest(ctx) {
+		return
+	}
+
+	// Bind request body
+	var embeddingsRequest embeddingsRequest
+	if err := ctx.ShouldBindJSON(&embeddingsRequest); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate request body, the model is optional since it is determined by the deployment
+	if err := utils.Validate.StructExcept(embeddingsRequest, "Model"); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		for _, fieldError := range validationErrors {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fieldError.Error()})
+			return
+		}
+	}
+	if embeddingsRequest.Model == "" {
+		embeddingsRequest.Model = provider.AzureDeployment(ctx)
+	}
+
+	inputs, err := embeddingsRequest.inputs()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	p.openAi.sendEmbeddingsResponse(ctx, embeddingsRequest, inputs)
+}
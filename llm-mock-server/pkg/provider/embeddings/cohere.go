@@ -0,0 +1,165 @@
+package embeddings
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+
+	"llm-mock-server/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	cohereEmbedPath      = "/v2/embed"
+	cohereMockId         = "cohere-embed-llm-mock"
+	cohereMockApiVersion = "2"
+	cohereResponseType   = "embeddings_by_type"
+	// cohereEmbeddingDimensions is the number of dimensions of the embeddings of embed-english-v3.0.
+	cohereEmbeddingDimensions = 1024
+
+	cohereEmbeddingTypeFloat   = "float"
+	cohereEmbeddingTypeInt8    = "int8"
+	cohereEmbeddingTypeUint8   = "uint8"
+	cohereEmbeddingTypeBinary  = "binary"
+	cohereEmbeddingTypeUbinary = "ubinary"
+)
+
+type cohereProvider struct{}
+
+func (p *cohereProvider) ShouldHandleRequest(ctx *gin.Context) bool {
+	return ctx.Request.URL.Path == cohereEmbedPath
+}
+
+func (p *cohereProvider) HandleEmbeddings(ctx *gin.Context) {
+	// Validate Authorization header
+	if ctx.GetHeader("Authorization") == "" {
+		p.sendErrorResponse(ctx, http.StatusUnauthorized, "no api key supplied")
+		return
+	}
+
+	// Bind request body
+	var embedRequest cohereEmbedRequest
+	if err := ctx.ShouldBindJSON(&embedRequest); err != nil {
+		p.sendErrorResponse(ctx, http.StatusBadRequest, fmt.Sprintf("invalid request: %v", err.Error()))
+		return
+	}
+
+	// Validate request body
+	if err := utils.Validate.Struct(embedRequest); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		for _, fieldError := range validationErrors {
+			p.sendErrorResponse(ctx, http.StatusBadRequest, fmt.Sprintf("invalid request: %v", fieldError.Error()))
+			return
+		}
+	}
+
+	var inputTokens int
+	for _, text := range embedRequest.Texts {
+		inputTokens += EstimateTokens(text)
+	}
+
+	embeddingTypes := embedRequest.EmbeddingTypes
+	if len(embeddingTypes) == 0 {
+		embeddingTypes = []string{cohereEmbeddingTypeFloat}
+	}
+	embeddings := make(map[string]any)
+	for _, embeddingType := range embeddingTypes {
+		vectors := make([]any, 0, len(embedRequest.Texts))
+		for _, text := range embedRequest.Texts {
+			vectors = append(vectors, quantizeEmbedding(createEmbeddingVector(embedRequest.Model, text, cohereEmbeddingDimensions), embeddingType))
+		}
+		embeddings[embeddingType] = vectors
+	}
+
+	ctx.JSON(http.StatusOK, cohereEmbedResponse{
+		Id:           cohereMockId,
+		Embeddings:   embeddings,
+		Texts:        embedRequest.Texts,
+		ResponseType: cohereResponseType,
+		Meta: cohereMeta{
+			ApiVersion:  cohereApiVersion{Version: cohereMockApiVersion},
+			BilledUnits: cohereBilledUnits{InputTokens: inputTokens},
+		},
+	})
+}
+
+func (p *cohereProvider) sendErrorResponse(ctx *gin.Context, statusCode int, message string) {
+	ctx.JSON(statusCode, cohereErrorResponse{
+		Id:      cohereMockId,
+		Message: message,
+	})
+}
+
+// quantizeEmbedding converts the float embedding into the given embedding type.
+// The int8 and uint8 types scale each dimension by the largest magnitude, while the binary and ubinary types
+// pack the sign bit of every dimension into bytes, with binary shifting the bytes into the signed range.
+func quantizeEmbedding(vector []float64, embeddingType string) any {
+	switch embeddingType {
+	case cohereEmbeddingTypeInt8, cohereEmbeddingTypeUint8:
+		var maxAbs float64
+		for _, v := range vector {
+			maxAbs = math.Max(maxAbs, math.Abs(v))
+		}
+		quantized := make([]int, len(vector))
+		for i, v := range vector {
+			if maxAbs > 0 {
+				quantized[i] = int(math.Round(v / maxAbs * 127))
+			}
+			if embeddingType == cohereEmbeddingTypeUint8 {
+				quantized[i] += 128
+			}
+		}
+		return quantized
+	case cohereEmbeddingTypeBinary, cohereEmbeddingTypeUbinary:
+		packed := make([]int, (len(vector)+7)/8)
+		for i, v := range vector {
+			if v > 0 {
+				packed[i/8] |= 1 << (7 - i%8)
+			}
+		}
+		if embeddingType == cohereEmbeddingTypeBinary {
+			for i := range packed {
+				packed[i] -= 128
+			}
+		}
+		return packed
+	default:
+		return vector
+	}
+}
+
+type cohereEmbedRequest struct {
+	Model          string   `json:"model" validate:"required"`
+	Texts          []string `json:"texts" validate:"required,min=1,max=96"`
+	InputType      string   `json:"input_type" validate:"required,oneof=search_document search_query classification clustering image"`
+	EmbeddingTypes []string `json:"embedding_types,omitempty" validate:"dive,oneof=float int8 uint8 binary ubinary"`
+	Truncate       string   `json:"truncate,omitempty"`
+}
+
+type cohereEmbedResponse struct {
+	Id           string         `json:"id"`
+	Embeddings   map[string]any `json:"embeddings"`
+	Texts        []string       `json:"texts"`
+	Meta         cohereMeta     `json:"meta"`
+	ResponseType string         `json:"response_type"`
+}
+
+type cohereMeta struct {
+	ApiVersion  cohereApiVersion  `json:"api_version"`
+	BilledUnits cohereBilledUnits `json:"billed_units"`
+}
+
+type cohereApiVersion struct {
+	Version string `json:"version"`
+}
+
+type cohereBilledUnits struct {
+	InputTokens int `json:"input_tokens"`
+}
+
+type cohereErrorResponse struct {
+	Id      string `json:"id"`
+	Message string `json:"message"`
+}
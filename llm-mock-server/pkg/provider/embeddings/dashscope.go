@@ -0,0 +1,172 @@
+package embeddings
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+
+	"llm-mock-server/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	dashScopeEmbeddingsPath = "/api/v1/services/embeddings/text-embedding/text-embedding"
+	dashScopeMockRequestId  = "dashscope-embedding-llm-mock"
+
+	// dashScopeMaxTextLength is the maximum number of tokens of each text.
+	dashScopeMaxTextLength = 8192
+
+	dashScopeErrorCodeInvalidApiKey    = "InvalidApiKey"
+	dashScopeErrorCodeInvalidParameter = "InvalidParameter"
+)
+
+// dashScopeModel describes the limits of a DashScope text embedding model.
+type dashScopeModel struct {
+	maxBatchSize      int
+	defaultDimensions int
+	// dimensions lists the supported dimensions, which can only be specified when not empty.
+	dimensions []int
+}
+
+var dashScopeModels = map[string]dashScopeModel{
+	"text-embedding-v1": {maxBatchSize: 25, defaultDimensions: 1536},
+	"text-embedding-v2": {maxBatchSize: 25, defaultDimensions: 1536},
+	"text-embedding-v3": {maxBatchSize: 10, defaultDimensions: 1024, dimensions: []int{1024, 768, 512, 256, 128, 64}},
+	"text-embedding-v4": {maxBatchSize: 10, defaultDimensions: 1024,
+		dimensions: []int{2048, 1536, 1024, 768, 512, 256, 128, 64}},
+}
+
+// dashScopeDefaultModel describes the models not known to the mock, such as the semantic mock models.
+var dashScopeDefaultModel = dashScopeModels["text-embedding-v2"]
+
+type dashScopeProvider struct{}
+
+func (p *dashScopeProvider) ShouldHandleRequest(ctx *gin.Context) bool {
+	return ctx.Request.URL.Path == dashScopeEmbeddingsPath
+}
+
+func (p *dashScopeProvider) HandleEmbeddings(ctx *gin.Context) {
+	// Validate Authorization header
+	if ctx.GetHeader("Authorization") == "" {
+		p.sendErrorResponse(ctx, http.StatusUnauthorized, dashScopeErrorCodeInvalidApiKey, "No API-key provided.")
+		return
+	}
+
+	// Bind request body
+	var embeddingRequest dashScopeEmbeddingRequest
+	if err := ctx.ShouldBindJSON(&embeddingRequest); err != nil {
+		p.sendErrorResponse(ctx, http.StatusBadRequest, dashScopeErrorCodeInvalidParameter,
+			fmt.Sprintf("invalid params: %v", err.Error()))
+		return
+	}
+
+	// Validate request body
+	if err := utils.Validate.Struct(embeddingRequest); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		for _, fieldError := range validationErrors {
+			p.sendErrorResponse(ctx, http.StatusBadRequest, dashScopeErrorCodeInvalidParameter,
+				fmt.Sprintf("invalid params: %v", fieldError.Error()))
+			return
+		}
+	}
+
+	model, ok := dashScopeModels[embeddingRequest.Model]
+	if !ok {
+		model = dashScopeDefaultModel
+	}
+	texts := embeddingRequest.Input.Texts
+	if len(texts) > model.maxBatchSize {
+		p.sendErrorResponse(ctx, http.StatusBadRequest, dashScopeErrorCodeInvalidParameter,
+			fmt.Sprintf("batch size is invalid, it should not be larger than %d.: input.contents", model.maxBatchSize))
+		return
+	}
+
+	dimensions := model.defaultDimensions
+	if embeddingRequest.Parameters.Dimension != 0 {
+		if len(model.dimensions) == 0 {
+			p.sendErrorResponse(ctx, http.StatusBadRequest, dashScopeErrorCodeInvalidParameter,
+				fmt.Sprintf("Model %s does not support parameters.dimension", embeddingRequest.Model))
+			return
+		}
+		if !slices.Contains(model.dimensions, embeddingRequest.Parameters.Dimension) {
+			p.sendErrorResponse(ctx, http.StatusBadRequest, dashScopeErrorCodeInvalidParameter,
+				fmt.Sprintf("Value error, dimension should be in %v: parameters.dimension", model.dimensions))
+			return
+		}
+		dimensions = embeddingRequest.Parameters.Dimension
+	}
+
+	var totalTokens int
+	embeddings := make([]dashScopeEmbedding, 0, len(texts))
+	for i, text := range texts {
+		tokens := EstimateTokens(text)
+		if text == "" || tokens > dashScopeMaxTextLength {
+			p.sendErrorResponse(ctx, http.StatusBadRequest, dashScopeErrorCodeInvalidParameter,
+				fmt.Sprintf("Range of input length should be [1, %d]: input.contents[%d]", dashScopeMaxTextLength, i))
+			return
+		}
+		totalTokens += tokens
+		embeddings = append(embeddings, dashScopeEmbedding{
+			TextIndex: i,
+			Embedding: createEmbeddingVector(embeddingRequest.Model, text, dimensions),
+		})
+	}
+
+	ctx.JSON(http.StatusOK, dashScopeEmbeddingResponse{
+		Output:    dashScopeEmbeddingOutput{Embeddings: embeddings},
+		Usage:     dashScopeUsage{TotalTokens: totalTokens},
+		RequestId: dashScopeMockRequestId,
+	})
+}
+
+func (p *dashScopeProvider) sendErrorResponse(ctx *gin.Context, statusCode int, errorCode, errorMsg string) {
+	ctx.JSON(statusCode, dashScopeErrorResponse{
+		Code:      errorCode,
+		Message:   errorMsg,
+		RequestId: dashScopeMockRequestId,
+	})
+}
+
+type dashScopeEmbeddingRequest struct {
+	Model      string                       `json:"model" validate:"required"`
+	Input      dashScopeEmbeddingInput      `json:"input"`
+	Parameters dashScopeEmbeddingParameters `json:"parameters,omitempty"`
+}
+
+type dashScopeEmbeddingInput struct {
+	Texts []string `json:"texts" validate:"required,min=1"`
+}
+
+type dashScopeEmbeddingParameters struct {
+	// TextType is either "query" or "document", which is the default.
+	TextType   string `json:"text_type,omitempty" validate:"omitempty,oneof=query document"`
+	Dimension  int    `json:"dimension,omitempty"`
+	OutputType string `json:"output_type,omitempty" validate:"omitempty,oneof=dense"`
+}
+
+type dashScopeEmbeddingResponse struct {
+	Output    dashScopeEmbeddingOutput `json:"output"`
+	Usage     dashScopeUsage           `json:"usage"`
+	RequestId string                   `json:"request_id"`
+}
+
+type dashScopeEmbeddingOutput struct {
+	Embeddings []dashScopeEmbedding `json:"embeddings"`
+}
+
+type dashScopeEmbedding struct {
+	TextIndex int       `json:"text_index"`
+	Embedding []float64 `json:"embedding"`
+}
+
+type dashScopeUsage struct {
+	TotalTokens int `json:"total_tokens"`
+}
+
+type dashScopeErrorResponse struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestId string `json:"request_id"`
+}
@@ -0,0 +1,290 @@
+package embeddings
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"llm-mock-server/pkg/provider"
+	"llm-mock-server/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	geminiActionEmbedContent       = "embedContent"
+	geminiActionBatchEmbedContents = "batchEmbedContents"
+	vertexActionPredict            = "predict"
+	vertexPublisherGoogle          = "google"
+
+	geminiTaskTypeRetrievalDocument = "RETRIEVAL_DOCUMENT"
+	// geminiMaxBatchSize is the maximum number of requests in a batchEmbedContents request.
+	geminiMaxBatchSize = 100
+	// vertexMaxInstances is the maximum number of instances in a predict request of text-embedding-004.
+	vertexMaxInstances = 250
+
+	googleStatusInvalidArgument = "INVALID_ARGUMENT"
+)
+
+// googleModelDimensions lists the default dimensions of the Google embedding models.
+var googleModelDimensions = map[string]int{
+	"embedding-001":                   768,
+	"text-embedding-004":              768,
+	"text-embedding-005":              768,
+	"text-multilingual-embedding-002": 768,
+	"gemini-embedding-001":            3072,
+	"gemini-embedding-exp-03-07":      3072,
+}
+
+// googleDefaultDimensions is the number of dimensions of the embeddings of Google models not known to the mock.
+const googleDefaultDimensions = 768
+
+// GeminiActions and VertexActions list the embedding actions of the Google model routes, which are shared with
+// the chat actions.
+var (
+	GeminiActions = []string{geminiActionEmbedContent, geminiActionBatchEmbedContents}
+	VertexActions = []string{vertexActionPredict}
+)
+
+// geminiProvider handles the embedding requests of the Gemini API and Vertex AI, which are authenticated before
+// being dispatched to the embeddings handlers.
+type geminiProvider struct{}
+
+func (p *geminiProvider) ShouldHandleRequest(ctx *gin.Context) bool {
+	_, action := provider.ModelAction(ctx)
+	switch action {
+	case geminiActionEmbedContent, geminiActionBatchEmbedContents:
+		return true
+	case vertexActionPredict:
+		return ctx.Param("publisher") == vertexPublisherGoogle
+	}
+	return false
+}
+
+func (p *geminiProvider) HandleEmbeddings(ctx *gin.Context) {
+	model, action := provider.ModelAction(ctx)
+	switch action {
+	case geminiActionEmbedContent:
+		p.handleEmbedContent(ctx, model)
+	case geminiActionBatchEmbedContents:
+		p.handleBatchEmbedContents(ctx, model)
+	case vertexActionPredict:
+		p.handlePredict(ctx, model)
+	}
+}
+
+func (p *geminiProvider) handleEmbedContent(ctx *gin.Context, model string) {
+	var embedRequest geminiEmbedContentRequest
+	if !p.bindRequest(ctx, &embedRequest) {
+		return
+	}
+	values, err := p.embedContent(model, embedRequest)
+	if err != nil {
+		p.sendErrorResponse(ctx, http.StatusBadRequest, googleStatusInvalidArgument, err.Error())
+		return
+	}
+	ctx.JSON(http.StatusOK, geminiEmbedContentResponse{Embedding: geminiContentEmbedding{Values: values}})
+}
+
+func (p *geminiProvider) handleBatchEmbedContents(ctx *gin.Context, model string) {
+	var batchRequest geminiBatchEmbedContentsRequest
+	if !p.bindRequest(ctx, &batchRequest) {
+		return
+	}
+	if len(batchRequest.Requests) > geminiMaxBatchSize {
+		p.sendErrorResponse(ctx, http.StatusBadRequest, googleStatusInvalidArgument,
+			fmt.Sprintf("* BatchEmbedContentsRequest.requests: at most %d requests can be in one batch", geminiMaxBatchSize))
+		return
+	}
+
+	embeddings := make([]geminiContentEmbedding, 0, len(batchRequest.Requests))
+	for i, embedRequest := range batchRequest.Requests {
+		// Every request must be for the model in the path
+		if strings.TrimPrefix(embedRequest.Model, "models/") != model {
+			p.sendErrorResponse(ctx, http.StatusBadRequest, googleStatusInvalidArgument,
+				fmt.Sprintf("* BatchEmbedContentsRequest.requests[%d].model: model names in the requests must match the model %s in the path", i, model))
+			return
+		}
+		values, err := p.embedContent(model, embedRequest)
+		if err != nil {
+			p.sendErrorResponse(ctx, http.StatusBadRequest, googleStatusInvalidArgument,
+				fmt.Sprintf("* BatchEmbedContentsRequest.requests[%d]: %v", i, err))
+			return
+		}
+		embeddings = append(embeddings, geminiContentEmbedding{Values: values})
+	}
+	ctx.JSON(http.StatusOK, geminiBatchEmbedContentsResponse{Embeddings: embeddings})
+}
+
+// embedContent returns the embedding of the text parts of the content.
+func (p *geminiProvider) embedContent(model string, embedRequest geminiEmbedContentRequest) ([]float64, error) {
+	if embedRequest.Title != "" && embedRequest.TaskType != geminiTaskTypeRetrievalDocument {
+		return nil, fmt.Errorf("title is only valid with task type %s", geminiTaskTypeRetrievalDocument)
+	}
+	dimensions, err := p.dimensions(model, embedRequest.OutputDimensionality)
+	if err != nil {
+		return nil, err
+	}
+	var texts []string
+	for _, part := range embedRequest.Content.Parts {
+		texts = append(texts, part.Text)
+	}
+	text := strings.Join(texts, "\n")
+	if text == "" {
+		return nil, fmt.Errorf("* EmbedContentRequest.content: contents must not be empty")
+	}
+	return createEmbeddingVector(model, text, dimensions), nil
+}
+
+func (p *geminiProvider) handlePredict(ctx *gin.Context, model string) {
+	var predictRequest vertexPredictRequest
+	if !p.bindRequest(ctx, &predictRequest) {
+		return
+	}
+	if len(predictRequest.Instances) > vertexMaxInstances {
+		p.sendErrorResponse(ctx, http.StatusBadRequest, googleStatusInvalidArgument,
+			fmt.Sprintf("Unable to submit request because the number of instances is %d, which exceeds the limit of %d.",
+				len(predictRequest.Instances), vertexMaxInstances))
+		return
+	}
+	dimensions, err := p.dimensions(model, predictRequest.Parameters.OutputDimensionality)
+	if err != nil {
+		p.sendErrorResponse(ctx, http.StatusBadRequest, googleStatusInvalidArgument, err.Error())
+		return
+	}
+
+	var billableCharacters int
+	predictions := make([]vertexPrediction, 0, len(predictRequest.Instances))
+	for i, instance := range predictRequest.Instances {
+		if instance.Title != "" && instance.TaskType != geminiTaskTypeRetrievalDocument {
+			p.sendErrorResponse(ctx, http.StatusBadRequest, googleStatusInvalidArgument,
+				fmt.Sprintf("instances[%d]: title is only valid with task type %s", i, geminiTaskTypeRetrievalDocument))
+			return
+		}
+		billableCharacters += utf8.RuneCountInString(instance.Content)
+		predictions = append(predictions, vertexPrediction{
+			Embeddings: vertexEmbeddings{
+				Statistics: vertexEmbeddingStatistics{TokenCount: EstimateTokens(instance.Content)},
+				Values:     createEmbeddingVector(model, instance.Content, dimensions),
+			},
+		})
+	}
+	ctx.JSON(http.StatusOK, vertexPredictResponse{
+		Predictions: predictions,
+		Metadata:    vertexPredictMetadata{BillableCharacterCount: billableCharacters},
+	})
+}
+
+// dimensions returns the dimensions of the embeddings, which can be reduced by the output dimensionality.
+func (p *geminiProvider) dimensions(model string, outputDimensionality int) (int, error) {
+	modelDimensions, ok := googleModelDimensions[model]
+	if !ok {
+		modelDimensions = googleDefaultDimensions
+	}
+	if outputDimensionality == 0 {
+		return modelDimensions, nil
+	}
+	if outputDimensionality < 1 || outputDimensionality > modelDimensions {
+		return 0, fmt.Errorf("outputDimensionality must be between 1 and %d, but got %d", modelDimensions, outputDimensionality)
+	}
+	return outputDimensionality, nil
+}
+
+// bindRequest binds and validates the request body, and sends the error response if it is invalid.
+func (p *geminiProvider) bindRequest(ctx *gin.Context, request any) bool {
+	// Bind request body
+	if err := ctx.ShouldBindJSON(request); err != nil {
+		p.sendErrorResponse(ctx, http.StatusBadRequest, googleStatusInvalidArgument,
+			fmt.Sprintf("Invalid JSON payload received. %v", err.Error()))
+		return false
+	}
+
+	// Validate request body
+	if err := utils.Validate.Struct(request); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		for _, fieldError := range validationErrors {
+			p.sendErrorResponse(ctx, http.StatusBadRequest, googleStatusInvalidArgument,
+				fmt.Sprintf("Invalid request: %v", fieldError.Error()))
+			return false
+		}
+	}
+	return true
+}
+
+func (p *geminiProvider) sendErrorResponse(ctx *gin.Context, statusCode int, status, message string) {
+	provider.SendGoogleErrorResponse(ctx, statusCode, status, message)
+}
+
+type geminiEmbedContentRequest struct {
+	Model                string        `json:"model,omitempty"`
+	Content              geminiContent `json:"content"`
+	TaskType             string        `json:"taskType,omitempty" validate:"omitempty,oneof=TASK_TYPE_UNSPECIFIED RETRIEVAL_QUERY RETRIEVAL_DOCUMENT SEMANTIC_SIMILARITY CLASSIFICATION CLUSTERING QUESTION_ANSWERING FACT_VERIFICATION CODE_RETRIEVAL_QUERY"`
+	Title                string        `json:"title,omitempty"`
+	OutputDimensionality int           `json:"outputDimensionality,omitempty"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts" validate:"required,min=1"`
+}
+
+type geminiPart struct {
+	Text string `json:"text"`
+}
+
+type geminiBatchEmbedContentsRequest struct {
+	Requests []geminiEmbedContentRequest `json:"requests" validate:"required,min=1,dive"`
+}
+
+type geminiEmbedContentResponse struct {
+	Embedding geminiContentEmbedding `json:"embedding"`
+}
+
+type geminiBatchEmbedContentsResponse struct {
+	Embeddings []geminiContentEmbedding `json:"embeddings"`
+}
+
+type geminiContentEmbedding struct {
+	Values []float64 `json:"values"`
+}
+
+type vertexPredictRequest struct {
+	Instances  []vertexInstance        `json:"instances" validate:"required,min=1"`
+	Parameters vertexPredictParameters `json:"parameters,omitempty"`
+}
+
+type vertexInstance struct {
+	Content  string `json:"content"`
+	TaskType string `json:"task_type,omitempty"`
+	Title    string `json:"title,omitempty"`
+}
+
+type vertexPredictParameters struct {
+	AutoTruncate         *bool `json:"autoTruncate,omitempty"`
+	OutputDimensionality int   `json:"outputDimensionality,omitempty"`
+}
+
+type vertexPredictResponse struct {
+	Predictions []vertexPrediction    `json:"predictions"`
+	Metadata    vertexPredictMetadata `json:"metadata"`
+}
+
+type vertexPrediction struct {
+	Embeddings vertexEmbeddings `json:"embeddings"`
+}
+
+type vertexEmbeddings struct {
+	Statistics vertexEmbeddingStatistics `json:"statistics"`
+	Values     []float64                 `json:"values"`
+}
+
+type vertexEmbeddingStatistics struct {
+	Truncated  bool `json:"truncated"`
+	TokenCount int  `json:"token_count"`
+}
+
+type vertexPredictMetadata struct {
+	BillableCharacterCount int `json:"billableCharacterCount"`
+}
@@ -0,0 +1,167 @@
+package embeddings
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	objectList      = "list"
+	objectEmbedding = "embedding"
+
+	// embeddingDefaultDimensions is the number of dimensions of the embeddings of models not known to the mock.
+	embeddingDefaultDimensions = 1536
+	// embeddingCharsPerToken is the average number of characters per token used to estimate the token count.
+	embeddingCharsPerToken = 4
+)
+
+type embeddingsRequest struct {
+	Input          any    `json:"input" validate:"required"`
+	Model          string `json:"model" validate:"required"`
+	EncodingFormat string `json:"encoding_format,omitempty"`
+	Dimensions     int    `json:"dimensions,omitempty"`
+	User           string `json:"user,omitempty"`
+}
+
+type embeddingsResponse struct {
+	Object string      `json:"object"`
+	Data   []embedding `json:"data"`
+	Model  string      `json:"model"`
+	Usage  usage       `json:"usage"`
+}
+
+type embedding struct {
+	Object    string `json:"object"`
+	Index     int    `json:"index"`
+	Embedding any    `json:"embedding"`
+}
+
+type usage struct {
+	PromptTokens int `json:"prompt_tokens"`
+	TotalTokens  int `json:"total_tokens"`
+}
+
+// embeddingInput is an input to embed, whose text of a token array is the space separated token IDs.
+type embeddingInput struct {
+	Text   string
+	Tokens int
+}
+
+// inputs returns the inputs, which are either a string, a list of strings, a token array or a list of token arrays.
+func (r *embeddingsRequest) inputs() ([]embeddingInput, error) {
+	if text, ok := r.Input.(string); ok {
+		return []embeddingInput{newTextInput(text)}, nil
+	}
+	items, ok := r.Input.([]any)
+	if !ok || len(items) == 0 {
+		return nil, fmt.Errorf("input must be a string, an array of strings, an array of tokens or an array of token arrays")
+	}
+	// A single token array like [1, 2, 3] is one input
+	if _, ok := items[0].(float64); ok {
+		input, err := newTokensInput(items)
+		if err != nil {
+			return nil, err
+		}
+		return []embeddingInput{input}, nil
+	}
+	inputs := make([]embeddingInput, 0, len(items))
+	for i, item := range items {
+		switch item := item.(type) {
+		case string:
+			inputs = append(inputs, newTextInput(item))
+		case []any:
+			input, err := newTokensInput(item)
+			if err != nil {
+				return nil, fmt.Errorf("input[%d]: %v", i, err)
+			}
+			inputs = append(inputs, input)
+		default:
+			return nil, fmt.Errorf("input[%d] must be a string or an array of tokens", i)
+		}
+	}
+	return inputs, nil
+}
+
+func newTextInput(text string) embeddingInput {
+	return embeddingInput{Text: text, Tokens: EstimateTokens(text)}
+}
+
+func newTokensInput(tokens []any) (embeddingInput, error) {
+	ids := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		id, ok := token.(float64)
+		if !ok || id != math.Trunc(id) || id < 0 {
+			return embeddingInput{}, fmt.Errorf("tokens must be non-negative integers")
+		}
+		ids = append(ids, strconv.FormatInt(int64(id), 10))
+	}
+	return embeddingInput{Text: strings.Join(ids, " "), Tokens: len(tokens)}, nil
+}
+
+// EstimateTokens estimates the number of tokens of the text, which is at least one.
+func EstimateTokens(text string) int {
+	return max(1, (utf8.RuneCountInString(text)+embeddingCharsPerToken-1)/embeddingCharsPerToken)
+}
+
+// createEmbeddingVector returns the embedding of the text generated in the mode of the model.
+func createEmbeddingVector(model, text string, dimensions int) []float64 {
+	if embeddingModeOf(model) == embeddingModeSemantic {
+		return createSemanticEmbeddingVector(text, dimensions)
+	}
+	return createHashEmbeddingVector(text, dimensions)
+}
+
+// createHashEmbeddingVector returns the unit vector of the given dimensions derived from the hash of the text, so
+// that the same text always gets the same embedding. A vector of fewer dimensions is the normalized prefix of the
+// vector of more dimensions, as if the embedding was shortened.
+func createHashEmbeddingVector(text string, dimensions int) []float64 {
+	vector := make([]float64, dimensions)
+	var block [sha256.Size]byte
+	var norm float64
+	for i := range vector {
+		// Each hash block provides eight 32-bit values in [-1, 1]
+		offset := i % (sha256.Size / 4)
+		if offset == 0 {
+			block = sha256.Sum256([]byte(fmt.Sprintf("%d:%s", i/(sha256.Size/4), text)))
+		}
+		value := binary.LittleEndian.Uint32(block[offset*4:])
+		vector[i] = float64(value)/math.MaxUint32*2 - 1
+		norm += vector[i] * vector[i]
+	}
+	norm = math.Sqrt(norm)
+	for i := range vector {
+		vector[i] /= norm
+	}
+	return vector
+}
+
+// createUsage returns the usage counting the tokens of every input.
+func createUsage(inputs []embeddingInput) usage {
+	var tokens int
+	for _, input := range inputs {
+		tokens += input.Tokens
+	}
+	return usage{PromptTokens: tokens, TotalTokens: tokens}
+}
+
+func createEmbeddingsResponse(model string, inputs []embeddingInput, dimensions int) embeddingsResponse {
+	var data []embedding
+	for i, input := range inputs {
+		data = append(data, embedding{
+			Object:    objectEmbedding,
+			Index:     i,
+			Embedding: createEmbeddingVector(model, input.Text, dimensions),
+		})
+	}
+	return embeddingsResponse{
+		Object: objectList,
+		Data:   data,
+		Model:  model,
+		Usage:  createUsage(inputs),
+	}
+}
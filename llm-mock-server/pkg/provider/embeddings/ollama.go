@@ -0,0 +1,69 @@
+package embeddings
+
+import (
+	"net/http"
+
+	"llm-mock-server/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	ollamaEmbedPath = "/api/embed"
+	// ollamaEmbeddingDimensions is the number of dimensions of the embeddings of nomic-embed-text.
+	ollamaEmbeddingDimensions = 768
+
+	// Mock timings in nanoseconds
+	ollamaMockTotalDuration = 14_143_917
+	ollamaMockLoadDuration  = 1_019_500
+)
+
+type ollamaProvider struct{}
+
+func (p *ollamaProvider) ShouldHandleRequest(ctx *gin.Context) bool {
+	return ctx.Request.URL.Path == ollamaEmbedPath
+}
+
+func (p *ollamaProvider) HandleEmbeddings(ctx *gin.Context) {
+	// Bind request body
+	var embeddingsRequest embeddingsRequest
+	if err := ctx.ShouldBindJSON(&embeddingsRequest); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate request body
+	if err := utils.Validate.Struct(embeddingsRequest); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		for _, fieldError := range validationErrors {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fieldError.Error()})
+			return
+		}
+	}
+
+	inputs, err := embeddingsRequest.inputs()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var embeddings [][]float64
+	for _, input := range inputs {
+		embeddings = append(embeddings, createEmbeddingVector(embeddingsRequest.Model, input.Text, ollamaEmbeddingDimensions))
+	}
+	ctx.JSON(http.StatusOK, ollamaEmbedResponse{
+		Model:           embeddingsRequest.Model,
+		Embeddings:      embeddings,
+		TotalDuration:   ollamaMockTotalDuration,
+		LoadDuration:    ollamaMockLoadDuration,
+		PromptEvalCount: createUsage(inputs).PromptTokens,
+	})
+}
+
+type ollamaEmbedResponse struct {
+	Model           string      `json:"model"`
+	Embeddings      [][]float64 `json:"embeddings"`
+	TotalDuration   int64       `json:"total_duration"`
+	LoadDuration    int64       `json:"load_duration"`
+	PromptEvalCount int         `json:"prompt_eval_count"`
+}
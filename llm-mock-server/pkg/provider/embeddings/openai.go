@@ -0,0 +1,140 @@
+package embeddings
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"llm-mock-server/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	openAiEmbeddingsPath = "/v1/embeddings"
+
+	openAiEncodingFormatFloat  = "float"
+	openAiEncodingFormatBase64 = "base64"
+	// openAiMaxInputs is the maximum number of inputs in a request.
+	openAiMaxInputs = 2048
+
+	openAiErrorTypeInvalidRequest = "invalid_request_error"
+)
+
+// openAiModelDimensions lists the default dimensions of the OpenAI embedding models.
+var openAiModelDimensions = map[string]int{
+	"text-embedding-ada-002": 1536,
+	"text-embedding-3-small": 1536,
+	"text-embedding-3-large": 3072,
+}
+
+type openAiProvider struct{}
+
+func (p *openAiProvider) ShouldHandleRequest(ctx *gin.Context) bool {
+	return ctx.Request.URL.Path == openAiEmbeddingsPath
+}
+
+func (p *openAiProvider) HandleEmbeddings(ctx *gin.Context) {
+	// Bind request body
+	var embeddingsRequest embeddingsRequest
+	if err := ctx.ShouldBindJSON(&embeddingsRequest); err != nil {
+		p.sendErrorResponse(ctx, "", err.Error())
+		return
+	}
+
+	// Validate request body
+	if err := utils.Validate.Struct(embeddingsRequest); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		for _, fieldError := range validationErrors {
+			p.sendErrorResponse(ctx, strings.ToLower(fieldError.Field()), fieldError.Error())
+			return
+		}
+	}
+
+	inputs, err := embeddingsRequest.inputs()
+	if err != nil {
+		p.sendErrorResponse(ctx, "input", fmt.Sprintf("'$.input' is invalid: %v", err))
+		return
+	}
+	if len(inputs) > openAiMaxInputs {
+		p.sendErrorResponse(ctx, "input", fmt.Sprintf("'$.input' is invalid: at most %d inputs are allowed", openAiMaxInputs))
+		return
+	}
+
+	p.sendEmbeddingsResponse(ctx, embeddingsRequest, inputs)
+}
+
+// sendEmbeddingsResponse sends the embeddings of the inputs in the requested dimensions and encoding format.
+func (p *openAiProvider) sendEmbeddingsResponse(ctx *gin.Context, embeddingsRequest embeddingsRequest, inputs []embeddingInput) {
+	dimensions, err := p.dimensions(embeddingsRequest)
+	if err != nil {
+		p.sendErrorResponse(ctx, "dimensions", err.Error())
+		return
+	}
+
+	response := createEmbeddingsResponse(embeddingsRequest.Model, inputs, dimensions)
+	switch embeddingsRequest.EncodingFormat {
+	case "", openAiEncodingFormatFloat:
+	case openAiEncodingFormatBase64:
+		for i := range response.Data {
+			response.Data[i].Embedding = encodeEmbeddingBase64(response.Data[i].Embedding.([]float64))
+		}
+	default:
+		p.sendErrorResponse(ctx, "encoding_format",
+			fmt.Sprintf("'%s' is not one of ['float', 'base64'] - 'encoding_format'", embeddingsRequest.EncodingFormat))
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+// dimensions returns the dimensions of the embeddings, which can only be reduced for the text-embedding-3 models.
+func (p *openAiProvider) dimensions(embeddingsRequest embeddingsRequest) (int, error) {
+	modelDimensions, found := openAiModelDimensions[embeddingsRequest.Model]
+	if !found {
+		modelDimensions = embeddingDefaultDimensions
+	}
+	if embeddingsRequest.Dimensions == 0 {
+		return modelDimensions, nil
+	}
+	if found && !strings.HasPrefix(embeddingsRequest.Model, "text-embedding-3") {
+		return 0, fmt.Errorf("This model does not support specifying dimensions.")
+	}
+	if embeddingsRequest.Dimensions < 1 || embeddingsRequest.Dimensions > modelDimensions {
+		return 0, fmt.Errorf("Invalid value for 'dimensions' = %d. Must be between 1 and %d.", embeddingsRequest.Dimensions, modelDimensions)
+	}
+	return embeddingsRequest.Dimensions, nil
+}
+
+func (p *openAiProvider) sendErrorResponse(ctx *gin.Context, param, message string) {
+	ctx.JSON(http.StatusBadRequest, openAiErrorResponse{
+		Error: openAiError{
+			Message: message,
+			Type:    openAiErrorTypeInvalidRequest,
+			Param:   param,
+		},
+	})
+}
+
+// encodeEmbeddingBase64 encodes the embedding as the base64 of its little-endian float32 values.
+func encodeEmbeddingBase64(vector []float64) string {
+	var buf bytes.Buffer
+	for _, v := range vector {
+		_ = binary.Write(&buf, binary.LittleEndian, float32(v))
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+type openAiErrorResponse struct {
+	Error openAiError `json:"error"`
+}
+
+type openAiError struct {
+	Message string  `json:"message"`
+	Type    string  `json:"type"`
+	Param   string  `json:"param"`
+	Code    *string `json:"code"`
+}
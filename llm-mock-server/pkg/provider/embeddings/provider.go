@@ -13,10 +13,22 @@ type requestHandler interface {
 	HandleEmbeddings(context *gin.Context)
 }
 
-var chatCompletionsHandlers []requestHandler
+var (
+	// openAi is shared by the providers whose embeddings are compatible with OpenAI's.
+	openAi = &openAiProvider{}
+
+	embeddingsHandlers = []requestHandler{
+		&azureProvider{openAi: openAi},
+		&ollamaProvider{},
+		&cohereProvider{},
+		&dashScopeProvider{},
+		&geminiProvider{},
+		openAi,
+	}
+)
 
 func HandleEmbeddings(context *gin.Context) {
-	for _, handler := range chatCompletionsHandlers {
+	for _, handler := range embeddingsHandlers {
 		if handler.ShouldHandleRequest(context) {
 			handler.HandleEmbeddings(context)
 			return
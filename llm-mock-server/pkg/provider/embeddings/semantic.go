@@ -0,0 +1,185 @@
+package embeddings
+
+import (
+	"hash/fnv"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// embeddingMode is the way the mock embeddings are generated.
+type embeddingMode int
+
+const (
+	// embeddingModeHash derives the embedding from the hash of the whole text, so different texts are nearly
+	// orthogonal however similar they are.
+	embeddingModeHash embeddingMode = iota
+	// embeddingModeSemantic derives the embedding from the hashed words and character n-grams of the text, so
+	// near-duplicate texts and synonyms get a high cosine similarity while unrelated texts stay nearly orthogonal.
+	embeddingModeSemantic
+)
+
+const (
+	// semanticEmbeddingModelKeyword selects the semantic mode for any model whose name contains it,
+	// such as "text-embedding-semantic".
+	semanticEmbeddingModelKeyword = "semantic"
+	// semanticNgramSize is the size of the character n-grams, which make typos and inflections similar.
+	semanticNgramSize = 3
+
+	semanticWordWeight  = 1.0
+	semanticNgramWeight = 0.5
+)
+
+// embeddingModelModes lists the models whose embeddings are not generated in the default hash mode.
+var embeddingModelModes = map[string]embeddingMode{
+	"mock-semantic-embedding": embeddingModeSemantic,
+}
+
+// embeddingSynonymGroups lists the groups of words embedded as the first word of their group. Words are matched
+// case-insensitively and as a whole, so Chinese synonyms only match when they are separated by punctuation or spaces.
+var embeddingSynonymGroups = [][]string{
+	{"hello", "hi", "hey", "greetings", "你好", "您好"},
+	{"goodbye", "bye", "farewell", "再见"},
+	{"weather", "forecast", "天气"},
+	{"big", "large", "huge", "大"},
+	{"small", "little", "tiny", "小"},
+	{"quick", "fast", "rapid", "快"},
+	{"buy", "purchase", "买", "购买"},
+	{"price", "cost", "价格"},
+	{"car", "automobile", "vehicle", "汽车"},
+	{"help", "assist", "support", "帮助"},
+	{"error", "bug", "fault", "错误"},
+	{"what's", "what is"},
+}
+
+// embeddingSynonyms maps every single-word synonym to the first word of its group.
+var embeddingSynonyms = func() map[string]string {
+	synonyms := make(map[string]string)
+	for _, group := range embeddingSynonymGroups {
+		for _, synonym := range group {
+			if !strings.Contains(synonym, " ") {
+				synonyms[synonym] = group[0]
+			}
+		}
+	}
+	return synonyms
+}()
+
+// embeddingPhraseSynonym is a multi-word synonym split into words.
+type embeddingPhraseSynonym struct {
+	words []string
+	word  string
+}
+
+// embeddingPhraseSynonyms lists the multi-word synonyms, the longest first so that they are matched first.
+var embeddingPhraseSynonyms = func() []embeddingPhraseSynonym {
+	var phrases []embeddingPhraseSynonym
+	for _, group := range embeddingSynonymGroups {
+		for _, synonym := range group {
+			if words := strings.Fields(synonym); len(words) > 1 {
+				phrases = append(phrases, embeddingPhraseSynonym{words: words, word: group[0]})
+			}
+		}
+	}
+	sort.SliceStable(phrases, func(i, j int) bool {
+		return len(phrases[i].words) > len(phrases[j].words)
+	})
+	return phrases
+}()
+
+// embeddingModeOf returns the mode in which the embeddings of the model are generated.
+func embeddingModeOf(model string) embeddingMode {
+	if mode, ok := embeddingModelModes[model]; ok {
+		return mode
+	}
+	if strings.Contains(strings.ToLower(model), semanticEmbeddingModelKeyword) {
+		return embeddingModeSemantic
+	}
+	return embeddingModeHash
+}
+
+// createSemanticEmbeddingVector returns the unit vector of the text built by feature hashing, in which every word and
+// character n-gram of the text adds its weight to a dimension chosen by its hash. Texts sharing most of their features
+// point in nearly the same direction.
+func createSemanticEmbeddingVector(text string, dimensions int) []float64 {
+	vector := make([]float64, dimensions)
+	for _, word := range semanticWords(text) {
+		addSemanticFeature(vector, "w:"+word, semanticWordWeight)
+		runes := []rune(" " + word + " ")
+		for i := 0; i+semanticNgramSize <= len(runes); i++ {
+			addSemanticFeature(vector, "n:"+string(runes[i:i+semanticNgramSize]), semanticNgramWeight)
+		}
+	}
+
+	var norm float64
+	for _, value := range vector {
+		norm += value * value
+	}
+	// A text without any word still gets a stable unit vector
+	if norm == 0 {
+		return createHashEmbeddingVector(text, dimensions)
+	}
+	norm = math.Sqrt(norm)
+	for i := range vector {
+		vector[i] /= norm
+	}
+	return vector
+}
+
+// semanticWords splits the lowercase text into words of letters, digits and apostrophes, replacing every synonym with
+// the first word of its group. Multi-word synonyms are matched on consecutive words.
+func semanticWords(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+	result := make([]string, 0, len(words))
+	for i := 0; i < len(words); i++ {
+		if phrase, ok := matchPhraseSynonym(words[i:]); ok {
+			result = append(result, phrase.word)
+			i += len(phrase.words) - 1
+			continue
+		}
+		if synonym, ok := embeddingSynonyms[words[i]]; ok {
+			result = append(result, synonym)
+		} else {
+			result = append(result, words[i])
+		}
+	}
+	return result
+}
+
+// matchPhraseSynonym returns the multi-word synonym the words start with.
+func matchPhraseSynonym(words []string) (embeddingPhraseSynonym, bool) {
+	for _, phrase := range embeddingPhraseSynonyms {
+		if len(phrase.words) <= len(words) && slices.Equal(phrase.words, words[:len(phrase.words)]) {
+			return phrase, true
+		}
+	}
+	return embeddingPhraseSynonym{}, false
+}
+
+// addSemanticFeature adds the weight of the feature to the dimension chosen by its hash, with a sign also chosen
+// by its hash so that colliding features cancel out rather than accumulate.
+func addSemanticFeature(vector []float64, feature string, weight float64) {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(feature))
+	sum := h.Sum64()
+	if sum>>63 == 1 {
+		weight = -weight
+	}
+	vector[sum%uint64(len(vector))] += weight
+}
+
+// SemanticSimilarity returns the cosine similarity of the semantic embeddings of the two texts, so that other mock
+// APIs can score texts consistently with the mock embeddings.
+func SemanticSimilarity(a, b string) float64 {
+	vectorA := createSemanticEmbeddingVector(a, embeddingDefaultDimensions)
+	vectorB := createSemanticEmbeddingVector(b, embeddingDefaultDimensions)
+	var similarity float64
+	for i := range vectorA {
+		similarity += vectorA[i] * vectorB[i]
+	}
+	return similarity
+}
@@ -0,0 +1,87 @@
+package embeddings
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSemanticWords(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{text: "Hi, what is the PRICE?", want: []string{"hello", "what's", "the", "price"}},
+		{text: "what island", want: []string{"what", "island"}},
+		{text: "somewhat isolated", want: []string{"somewhat", "isolated"}},
+		{text: "我想 购买 汽车", want: []string{"我想", "buy", "car"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if words := semanticWords(tt.text); !slices.Equal(words, tt.want) {
+				t.Errorf("words = %q, want %q", words, tt.want)
+			}
+		})
+	}
+}
+
+func TestSemanticSimilarity(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		minimum float64
+		maximum float64
+	}{
+		{
+			name:    "identical",
+			a:       "What is the weather in Beijing today?",
+			b:       "What is the weather in Beijing today?",
+			minimum: 0.999,
+			maximum: 1.001,
+		},
+		{
+			name:    "near-duplicate",
+			a:       "What is the weather in Beijing today?",
+			b:       "what is the wheather in Beijing today",
+			minimum: 0.8,
+			maximum: 1,
+		},
+		{
+			name:    "synonyms",
+			a:       "Hello, how can I buy a car?",
+			b:       "Hi, how can I purchase a vehicle?",
+			minimum: 0.95,
+			maximum: 1.001,
+		},
+		{
+			name:    "multi-word synonym",
+			a:       "What is the price?",
+			b:       "What's the cost?",
+			minimum: 0.95,
+			maximum: 1.001,
+		},
+		{
+			name:    "unrelated",
+			a:       "What is the weather in Beijing today?",
+			b:       "How do I reset my router password?",
+			minimum: -0.3,
+			maximum: 0.3,
+		},
+		{
+			name:    "synonym across word boundaries",
+			a:       "what island",
+			b:       "what'sland",
+			minimum: -1,
+			maximum: 0.8,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			similarity := SemanticSimilarity(tt.a, tt.b)
+			if similarity < tt.minimum || similarity > tt.maximum {
+				t.Errorf("similarity = %.3f, want between %.3f and %.3f", similarity, tt.minimum, tt.maximum)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package provider
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	_ "embed"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"llm-mock-server/pkg/log"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	VertexDomainSuffix = "aiplatform.googleapis.com"
+
+	GoogleStatusPermissionDenied = "PERMISSION_DENIED"
+	GoogleStatusUnauthenticated  = "UNAUTHENTICATED"
+
+	googleErrorInfoType         = "type.googleapis.com/google.rpc.ErrorInfo"
+	googleErrorDomainGoogleApis = "googleapis.com"
+	vertexPermissionPredict     = "aiplatform.endpoints.predict"
+)
+
+var (
+	// GeminiModelRoutes lists the routes of the Gemini API, whose last segment is "{model}:{action}".
+	GeminiModelRoutes = []string{
+		"/v1beta/models/:modelAction",
+		"/v1/models/:modelAction",
+	}
+	// VertexModelRoutes lists the routes of the Vertex AI publisher models, whose last segment is "{model}:{action}".
+	VertexModelRoutes = []string{
+		"/v1/projects/:project/locations/:location/publishers/:publisher/models/:modelAction",
+		"/v1beta1/projects/:project/locations/:location/publishers/:publisher/models/:modelAction",
+	}
+
+	// modelActionHandlers maps the model routes to the handlers of their actions.
+	modelActionHandlers = map[string]map[string]gin.HandlerFunc{}
+)
+
+// vertexMockServiceAccountKey is the key of the mock service account used when no key file is configured.
+//
+//go:embed vertex_service_account.json
+var vertexMockServiceAccountKey []byte
+
+// HandleModelActions registers the handler of the given actions on the model routes. gin can not tell the actions of
+// a route apart, so every route is registered once and its requests are dispatched by action, with 404 returned for
+// the actions without a handler.
+func HandleModelActions(server *gin.Engine, routes []string, actions []string, handler gin.HandlerFunc) {
+	for _, route := range routes {
+		handlers, found := modelActionHandlers[route]
+		if !found {
+			handlers = map[string]gin.HandlerFunc{}
+			modelActionHandlers[route] = handlers
+			server.POST(route, func(ctx *gin.Context) {
+				_, action := ModelAction(ctx)
+				if handler, ok := handlers[action]; ok {
+					handler(ctx)
+					return
+				}
+				ctx.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+			})
+		}
+		for _, action := range actions {
+			handlers[action] = handler
+		}
+	}
+}
+
+// ModelAction splits the last segment of a model route into the model and the action.
+func ModelAction(ctx *gin.Context) (string, string) {
+	model, action, _ := strings.Cut(ctx.Param("modelAction"), ":")
+	return model, action
+}
+
+// GoogleAuth authenticates the requests to the Google APIs, whose chat and embedding endpoints are served by different
+// packages. Gemini API requests carry an API key, while Vertex AI requests carry an access token issued to the service
+// account by the token endpoint.
+type GoogleAuth struct {
+	ServiceAccount *GoogleServiceAccount
+	AccessTokens   *AccessTokenStore
+}
+
+// NewGoogleAuth creates the authenticator accepting the access tokens issued to the service account in the key file,
+// or to the mock service account if the key file is not given.
+func NewGoogleAuth(keyFile string) *GoogleAuth {
+	key := vertexMockServiceAccountKey
+	if keyFile != "" {
+		var err error
+		if key, err = os.ReadFile(keyFile); err != nil {
+			log.Fatalf("Error reading Vertex AI service account key file: %v", err)
+		}
+	}
+	serviceAccount, err := ParseGoogleServiceAccount(key)
+	if err != nil {
+		log.Fatalf("Error parsing Vertex AI service account key: %v", err)
+	}
+	return &GoogleAuth{
+		ServiceAccount: serviceAccount,
+		AccessTokens:   NewAccessTokenStore(),
+	}
+}
+
+// AuthenticateGemini checks the API key of a Gemini API request, which is either passed in the query or in
+// the x-goog-api-key header, and sends the error response if it is missing.
+func (a *GoogleAuth) AuthenticateGemini(ctx *gin.Context) bool {
+	if ctx.Query("key") == "" && ctx.GetHeader("x-goog-api-key") == "" {
+		SendGoogleErrorResponse(ctx, http.StatusForbidden, GoogleStatusPermissionDenied,
+			"Method doesn't allow unregistered callers (callers without established identity). Please use API Key or other form of API consumer identity to call this API.")
+		return false
+	}
+	return true
+}
+
+// AuthenticateVertex checks the access token of a Vertex AI request for the resource in the given project, and sends
+// the error response if the token is invalid or the project is not the one of the service account.
+func (a *GoogleAuth) AuthenticateVertex(ctx *gin.Context, project, resource string) bool {
+	token := strings.TrimPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+	found, expired := a.AccessTokens.Lookup(token)
+	if !found || expired {
+		reason := "ACCESS_TOKEN_TYPE_UNSUPPORTED"
+		if expired {
+			reason = "ACCESS_TOKEN_EXPIRED"
+		}
+		SendGoogleErrorResponse(ctx, http.StatusUnauthorized, GoogleStatusUnauthenticated,
+			"Request had invalid authentication credentials. Expected OAuth 2 access token, login cookie or other valid authentication credential. See https://developers.google.com/identity/sign-in/web/devconsole-project.",
+			vertexErrorInfo(reason))
+		return false
+	}
+
+	// Tokens are only valid for the project of the service account
+	if project != a.ServiceAccount.ProjectId {
+		SendGoogleErrorResponse(ctx, http.StatusForbidden, GoogleStatusPermissionDenied,
+			fmt.Sprintf("Permission '%s' denied on resource '//%s/%s' (or it may not exist).",
+				vertexPermissionPredict, VertexDomainSuffix, resource),
+			vertexErrorInfo("IAM_PERMISSION_DENIED"))
+		return false
+	}
+	return true
+}
+
+// RequireGeminiApiKey wraps the handler of a Gemini API route to authenticate its requests first.
+func (a *GoogleAuth) RequireGeminiApiKey(handler gin.HandlerFunc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if a.AuthenticateGemini(ctx) {
+			handler(ctx)
+		}
+	}
+}
+
+// RequireVertexAccessToken wraps the handler of a Vertex AI model route to authenticate its requests first.
+func (a *GoogleAuth) RequireVertexAccessToken(handler gin.HandlerFunc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		model, _ := ModelAction(ctx)
+		resource := fmt.Sprintf("projects/%s/locations/%s/publishers/%s/models/%s",
+			ctx.Param("project"), ctx.Param("location"), ctx.Param("publisher"), model)
+		if a.AuthenticateVertex(ctx, ctx.Param("project"), resource) {
+			handler(ctx)
+		}
+	}
+}
+
+// SendGoogleErrorResponse sends an error response in the format shared by the Google APIs.
+func SendGoogleErrorResponse(ctx *gin.Context, statusCode int, status, message string, details ...GoogleErrorInfo) {
+	ctx.JSON(statusCode, GoogleErrorResponse{
+		Error: GoogleError{
+			Code:    statusCode,
+			Message: message,
+			Status:  status,
+			Details: details,
+		},
+	})
+}
+
+func vertexErrorInfo(reason string) GoogleErrorInfo {
+	return GoogleErrorInfo{
+		Type:   googleErrorInfoType,
+		Reason: reason,
+		Domain: googleErrorDomainGoogleApis,
+		Metadata: map[string]string{
+			"service": VertexDomainSuffix,
+		},
+	}
+}
+
+// GoogleServiceAccount is the service account key in the JSON format downloaded from Google Cloud.
+type GoogleServiceAccount struct {
+	ProjectId    string `json:"project_id"`
+	PrivateKeyId string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	ClientEmail  string `json:"client_email"`
+	TokenUri     string `json:"token_uri"`
+
+	PublicKey *rsa.PublicKey `json:"-"`
+}
+
+func ParseGoogleServiceAccount(key []byte) (*GoogleServiceAccount, error) {
+	var serviceAccount GoogleServiceAccount
+	if err := json.Unmarshal(key, &serviceAccount); err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode([]byte(serviceAccount.PrivateKey))
+	if block == nil {
+		return nil, fmt.Errorf("private_key is not in the PEM format")
+	}
+	privateKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := privateKey.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private_key is not an RSA key")
+	}
+	serviceAccount.PublicKey = &rsaKey.PublicKey
+	return &serviceAccount, nil
+}
+
+type GoogleErrorResponse struct {
+	Error GoogleError `json:"error"`
+}
+
+type GoogleError struct {
+	Code    int               `json:"code"`
+	Message string            `json:"message"`
+	Status  string            `json:"status"`
+	Details []GoogleErrorInfo `json:"details,omitempty"`
+}
+
+// GoogleErrorInfo describes the cause of the error in the details.
+type GoogleErrorInfo struct {
+	Type     string            `json:"@type"`
+	Reason   string            `json:"reason"`
+	Domain   string            `json:"domain"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
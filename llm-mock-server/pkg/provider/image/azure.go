@@ -0,0 +1,48 @@
+package image
+
+import (
+	"net/http"
+
+	"llm-mock-server/pkg/provider"
+	"llm-mock-server/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const azureImageGenerationsPathSuffix = "/images/generations"
+
+type azureProvider struct{}
+
+func (p *azureProvider) ShouldHandleRequest(ctx *gin.Context) bool {
+	return provider.IsAzureRequest(ctx, azureImageGenerationsPathSuffix)
+}
+
+func (p *azureProvider) HandleImageGenerations(ctx *gin.Context) {
+	if !provider.ValidateAzureRequest(ctx) {
+		return
+	}
+
+	// Bind request body
+	var imageRequest imageGenerationsRequest
+	if err := ctx.ShouldBindJSON(&imageRequest); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate request body
+	if err := utils.Validate.Struct(imageRequest); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		for _, fieldError := range validationErrors {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fieldError.Error()})
+			return
+		}
+	}
+
+	response := createImageGenerationsResponse(imageRequest)
+	response.PromptFilterResults = provider.NewAzurePromptFilterResults()
+	for i := range response.Data {
+		response.Data[i].ContentFilterResults = provider.NewAzureContentFilterResults()
+	}
+	ctx.JSON(http.StatusOK, response)
+}
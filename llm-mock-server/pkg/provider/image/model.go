@@ -0,0 +1,60 @@
+package image
+
+import "llm-mock-server/pkg/provider"
+
+const (
+	imageMockUrl = "https://llm-mock-server/images/llm-mock.png"
+	// imageMockB64Json is a base64-encoded 1x1 PNG image.
+	imageMockB64Json = "iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNkYPhfDwAChwGA60e6kgAAAABJRU5ErkJggg=="
+
+	responseFormatB64Json = "b64_json"
+)
+
+var imageMockCreated int64 = 10
+
+type imageGenerationsRequest struct {
+	Prompt         string `json:"prompt" validate:"required"`
+	Model          string `json:"model,omitempty"`
+	N              int    `json:"n,omitempty"`
+	Quality        string `json:"quality,omitempty"`
+	ResponseFormat string `json:"response_format,omitempty"`
+	Size           string `json:"size,omitempty"`
+	Style          string `json:"style,omitempty"`
+	User           string `json:"user,omitempty"`
+}
+
+type imageGenerationsResponse struct {
+	Created int64       `json:"created"`
+	Data    []imageData `json:"data"`
+	// PromptFilterResults is only returned by Azure OpenAI
+	PromptFilterResults []provider.AzurePromptFilterResult `json:"prompt_filter_results,omitempty"`
+}
+
+type imageData struct {
+	Url           string `json:"url,omitempty"`
+	B64Json       string `json:"b64_json,omitempty"`
+	RevisedPrompt string `json:"revised_prompt,omitempty"`
+	// ContentFilterResults is only returned by Azure OpenAI
+	ContentFilterResults provider.AzureContentFilterResults `json:"content_filter_results,omitempty"`
+}
+
+func createImageGenerationsResponse(imageRequest imageGenerationsRequest) imageGenerationsResponse {
+	n := 1
+	if imageRequest.N > 1 {
+		n = imageRequest.N
+	}
+	var data []imageData
+	for i := 0; i < n; i++ {
+		image := imageData{RevisedPrompt: imageRequest.Prompt}
+		if imageRequest.ResponseFormat == responseFormatB64Json {
+			image.B64Json = imageMockB64Json
+		} else {
+			image.Url = imageMockUrl
+		}
+		data = append(data, image)
+	}
+	return imageGenerationsResponse{
+		Created: imageMockCreated,
+		Data:    data,
+	}
+}
@@ -0,0 +1,28 @@
+package image
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"llm-mock-server/pkg/provider"
+)
+
+type requestHandler interface {
+	provider.CommonRequestHandler
+
+	HandleImageGenerations(context *gin.Context)
+}
+
+var imageGenerationsHandlers = []requestHandler{
+	&azureProvider{},
+}
+
+func HandleImageGenerations(context *gin.Context) {
+	for _, handler := range imageGenerationsHandlers {
+		if handler.ShouldHandleRequest(context) {
+			handler.HandleImageGenerations(context)
+			return
+		}
+	}
+	context.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+}
@@ -0,0 +1,110 @@
+package rerank
+
+import (
+	"fmt"
+	"net/http"
+
+	"llm-mock-server/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	cohereRerankPathV1 = "/v1/rerank"
+	cohereRerankPathV2 = "/v2/rerank"
+	cohereMockId       = "cohere-rerank-llm-mock"
+	// cohereSearchUnitDocuments is the number of documents billed as one search unit.
+	cohereSearchUnitDocuments = 100
+)
+
+type cohereProvider struct{}
+
+func (p *cohereProvider) ShouldHandleRequest(ctx *gin.Context) bool {
+	return ctx.Request.URL.Path == cohereRerankPathV1 || ctx.Request.URL.Path == cohereRerankPathV2
+}
+
+func (p *cohereProvider) HandleRerank(ctx *gin.Context) {
+	// Validate Authorization header
+	if ctx.GetHeader("Authorization") == "" {
+		p.sendErrorResponse(ctx, http.StatusUnauthorized, "no api key supplied")
+		return
+	}
+
+	// Bind request body
+	var rerankRequest cohereRerankRequest
+	if err := ctx.ShouldBindJSON(&rerankRequest); err != nil {
+		p.sendErrorResponse(ctx, http.StatusBadRequest, fmt.Sprintf("invalid request: %v", err.Error()))
+		return
+	}
+
+	// Validate request body
+	if err := utils.Validate.Struct(rerankRequest); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		for _, fieldError := range validationErrors {
+			p.sendErrorResponse(ctx, http.StatusBadRequest, fmt.Sprintf("invalid request: %v", fieldError.Error()))
+			return
+		}
+	}
+
+	documents, err := parseDocuments(rerankRequest.Documents)
+	if err != nil {
+		p.sendErrorResponse(ctx, http.StatusBadRequest, fmt.Sprintf("invalid request: %v", err.Error()))
+		return
+	}
+
+	apiVersion := "1"
+	if ctx.Request.URL.Path == cohereRerankPathV2 {
+		apiVersion = "2"
+	}
+	ctx.JSON(http.StatusOK, cohereRerankResponse{
+		Id:      cohereMockId,
+		Results: rankDocuments(rerankRequest.Query, documents, rerankRequest.TopN, rerankRequest.ReturnDocuments),
+		Meta: cohereMeta{
+			ApiVersion: cohereApiVersion{Version: apiVersion},
+			BilledUnits: cohereBilledUnits{
+				SearchUnits: (len(documents) + cohereSearchUnitDocuments - 1) / cohereSearchUnitDocuments,
+			},
+		},
+	})
+}
+
+func (p *cohereProvider) sendErrorResponse(ctx *gin.Context, statusCode int, message string) {
+	ctx.JSON(statusCode, cohereErrorResponse{
+		Id:      cohereMockId,
+		Message: message,
+	})
+}
+
+type cohereRerankRequest struct {
+	Model           string `json:"model" validate:"required"`
+	Query           string `json:"query" validate:"required"`
+	Documents       []any  `json:"documents" validate:"required,min=1,max=1000"`
+	TopN            int    `json:"top_n,omitempty" validate:"omitempty,gte=1"`
+	ReturnDocuments bool   `json:"return_documents,omitempty"`
+	MaxChunksPerDoc int    `json:"max_chunks_per_doc,omitempty"`
+}
+
+type cohereRerankResponse struct {
+	Id      string         `json:"id"`
+	Results []rerankResult `json:"results"`
+	Meta    cohereMeta     `json:"meta"`
+}
+
+type cohereMeta struct {
+	ApiVersion  cohereApiVersion  `json:"api_version"`
+	BilledUnits cohereBilledUnits `json:"billed_units"`
+}
+
+type cohereApiVersion struct {
+	Version string `json:"version"`
+}
+
+type cohereBilledUnits struct {
+	SearchUnits int `json:"search_units"`
+}
+
+type cohereErrorResponse struct {
+	Id      string `json:"id"`
+	Message string `json:"message"`
+}
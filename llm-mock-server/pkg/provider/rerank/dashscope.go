@@ -0,0 +1,119 @@
+package rerank
+
+import (
+	"fmt"
+	"net/http"
+
+	"llm-mock-server/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	dashScopeRerankPath    = "/api/v1/services/rerank/text-rerank/text-rerank"
+	dashScopeMockRequestId = "dashscope-rerank-llm-mock"
+	// dashScopeMaxDocuments is the maximum number of documents of gte-rerank.
+	dashScopeMaxDocuments = 500
+
+	dashScopeErrorCodeInvalidApiKey    = "InvalidApiKey"
+	dashScopeErrorCodeInvalidParameter = "InvalidParameter"
+)
+
+type dashScopeProvider struct{}
+
+func (p *dashScopeProvider) ShouldHandleRequest(ctx *gin.Context) bool {
+	return ctx.Request.URL.Path == dashScopeRerankPath
+}
+
+func (p *dashScopeProvider) HandleRerank(ctx *gin.Context) {
+	// Validate Authorization header
+	if ctx.GetHeader("Authorization") == "" {
+		p.sendErrorResponse(ctx, http.StatusUnauthorized, dashScopeErrorCodeInvalidApiKey, "No API-key provided.")
+		return
+	}
+
+	// Bind request body
+	var rerankRequest dashScopeRerankRequest
+	if err := ctx.ShouldBindJSON(&rerankRequest); err != nil {
+		p.sendErrorResponse(ctx, http.StatusBadRequest, dashScopeErrorCodeInvalidParameter,
+			fmt.Sprintf("invalid params: %v", err.Error()))
+		return
+	}
+
+	// Validate request body
+	if err := utils.Validate.Struct(rerankRequest); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		for _, fieldError := range validationErrors {
+			p.sendErrorResponse(ctx, http.StatusBadRequest, dashScopeErrorCodeInvalidParameter,
+				fmt.Sprintf("invalid params: %v", fieldError.Error()))
+			return
+		}
+	}
+
+	input := rerankRequest.Input
+	if len(input.Documents) > dashScopeMaxDocuments {
+		p.sendErrorResponse(ctx, http.StatusBadRequest, dashScopeErrorCodeInvalidParameter,
+			fmt.Sprintf("batch size is invalid, it should not be larger than %d.: input.documents", dashScopeMaxDocuments))
+		return
+	}
+	documents, err := parseDocuments(input.Documents)
+	if err != nil {
+		p.sendErrorResponse(ctx, http.StatusBadRequest, dashScopeErrorCodeInvalidParameter,
+			fmt.Sprintf("invalid params: %v", err.Error()))
+		return
+	}
+
+	parameters := rerankRequest.Parameters
+	ctx.JSON(http.StatusOK, dashScopeRerankResponse{
+		Output: dashScopeRerankOutput{
+			Results: rankDocuments(input.Query, documents, parameters.TopN, parameters.ReturnDocuments),
+		},
+		Usage:     dashScopeUsage{TotalTokens: countTokens(input.Query, documents)},
+		RequestId: dashScopeMockRequestId,
+	})
+}
+
+func (p *dashScopeProvider) sendErrorResponse(ctx *gin.Context, statusCode int, errorCode, errorMsg string) {
+	ctx.JSON(statusCode, dashScopeErrorResponse{
+		Code:      errorCode,
+		Message:   errorMsg,
+		RequestId: dashScopeMockRequestId,
+	})
+}
+
+type dashScopeRerankRequest struct {
+	Model      string                    `json:"model" validate:"required"`
+	Input      dashScopeRerankInput      `json:"input"`
+	Parameters dashScopeRerankParameters `json:"parameters,omitempty"`
+}
+
+type dashScopeRerankInput struct {
+	Query     string `json:"query" validate:"required"`
+	Documents []any  `json:"documents" validate:"required,min=1"`
+}
+
+type dashScopeRerankParameters struct {
+	TopN            int  `json:"top_n,omitempty" validate:"omitempty,gte=1"`
+	ReturnDocuments bool `json:"return_documents,omitempty"`
+}
+
+type dashScopeRerankResponse struct {
+	Output    dashScopeRerankOutput `json:"output"`
+	Usage     dashScopeUsage        `json:"usage"`
+	RequestId string                `json:"request_id"`
+}
+
+type dashScopeRerankOutput struct {
+	Results []rerankResult `json:"results"`
+}
+
+type dashScopeUsage struct {
+	TotalTokens int `json:"total_tokens"`
+}
+
+type dashScopeErrorResponse struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestId string `json:"request_id"`
+}
@@ -0,0 +1,88 @@
+package rerank
+
+import (
+	"fmt"
+	"net/http"
+
+	"llm-mock-server/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	jinaDomain     = "api.jina.ai"
+	jinaRerankPath = "/v1/rerank"
+)
+
+// jinaProvider handles the Jina reranker, whose path is the same as the Cohere v1 one, so requests are matched
+// by host.
+type jinaProvider struct{}
+
+func (p *jinaProvider) ShouldHandleRequest(ctx *gin.Context) bool {
+	return ctx.Request.Host == jinaDomain && ctx.Request.URL.Path == jinaRerankPath
+}
+
+func (p *jinaProvider) HandleRerank(ctx *gin.Context) {
+	// Validate Authorization header
+	if ctx.GetHeader("Authorization") == "" {
+		p.sendErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized: No API key provided.")
+		return
+	}
+
+	// Bind request body
+	var rerankRequest jinaRerankRequest
+	if err := ctx.ShouldBindJSON(&rerankRequest); err != nil {
+		p.sendErrorResponse(ctx, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	// Validate request body
+	if err := utils.Validate.Struct(rerankRequest); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		for _, fieldError := range validationErrors {
+			p.sendErrorResponse(ctx, http.StatusUnprocessableEntity, fieldError.Error())
+			return
+		}
+	}
+
+	documents, err := parseDocuments(rerankRequest.Documents)
+	if err != nil {
+		p.sendErrorResponse(ctx, http.StatusUnprocessableEntity, fmt.Sprintf("Invalid documents: %v", err))
+		return
+	}
+
+	// Documents are returned unless disabled explicitly
+	returnDocuments := rerankRequest.ReturnDocuments == nil || *rerankRequest.ReturnDocuments
+	ctx.JSON(http.StatusOK, jinaRerankResponse{
+		Model:   rerankRequest.Model,
+		Usage:   jinaUsage{TotalTokens: countTokens(rerankRequest.Query, documents)},
+		Results: rankDocuments(rerankRequest.Query, documents, rerankRequest.TopN, returnDocuments),
+	})
+}
+
+func (p *jinaProvider) sendErrorResponse(ctx *gin.Context, statusCode int, detail string) {
+	ctx.JSON(statusCode, jinaErrorResponse{Detail: detail})
+}
+
+type jinaRerankRequest struct {
+	Model           string `json:"model" validate:"required"`
+	Query           string `json:"query" validate:"required"`
+	Documents       []any  `json:"documents" validate:"required,min=1"`
+	TopN            int    `json:"top_n,omitempty" validate:"omitempty,gte=1"`
+	ReturnDocuments *bool  `json:"return_documents,omitempty"`
+}
+
+type jinaRerankResponse struct {
+	Model   string         `json:"model"`
+	Usage   jinaUsage      `json:"usage"`
+	Results []rerankResult `json:"results"`
+}
+
+type jinaUsage struct {
+	TotalTokens int `json:"total_tokens"`
+}
+
+type jinaErrorResponse struct {
+	Detail string `json:"detail"`
+}
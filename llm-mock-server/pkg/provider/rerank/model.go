@@ -0,0 +1,73 @@
+package rerank
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"llm-mock-server/pkg/provider/embeddings"
+)
+
+// rerankResult is a document ranked by its relevance to the query.
+type rerankResult struct {
+	Index          int             `json:"index"`
+	RelevanceScore float64         `json:"relevance_score"`
+	Document       *rerankDocument `json:"document,omitempty"`
+}
+
+type rerankDocument struct {
+	Text string `json:"text"`
+}
+
+// parseDocuments returns the texts of the documents, which are either strings or objects with a text field.
+func parseDocuments(documents []any) ([]string, error) {
+	texts := make([]string, 0, len(documents))
+	for i, document := range documents {
+		switch document := document.(type) {
+		case string:
+			texts = append(texts, document)
+		case map[string]any:
+			text, ok := document["text"].(string)
+			if !ok {
+				return nil, fmt.Errorf("documents[%d] must have a text field", i)
+			}
+			texts = append(texts, text)
+		default:
+			return nil, fmt.Errorf("documents[%d] must be a string or an object with a text field", i)
+		}
+	}
+	return texts, nil
+}
+
+// rankDocuments scores the documents by the similarity of their mock semantic embeddings to the query embedding,
+// and returns the topN most relevant ones in descending order of relevance, or all of them if topN is not positive.
+func rankDocuments(query string, documents []string, topN int, returnDocuments bool) []rerankResult {
+	results := make([]rerankResult, 0, len(documents))
+	for i, document := range documents {
+		result := rerankResult{
+			Index: i,
+			// Relevance scores are in [0, 1], so texts pointing away from the query are irrelevant
+			RelevanceScore: math.Max(0, embeddings.SemanticSimilarity(query, document)),
+		}
+		if returnDocuments {
+			result.Document = &rerankDocument{Text: document}
+		}
+		results = append(results, result)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].RelevanceScore > results[j].RelevanceScore
+	})
+	if topN > 0 && topN < len(results) {
+		results = results[:topN]
+	}
+	return results
+}
+
+// countTokens returns the estimated number of tokens of the query paired with every document.
+func countTokens(query string, documents []string) int {
+	var tokens int
+	for _, document := range documents {
+		tokens += embeddings.EstimateTokens(query) + embeddings.EstimateTokens(document)
+	}
+	return tokens
+}
@@ -0,0 +1,30 @@
+package rerank
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"llm-mock-server/pkg/provider"
+)
+
+type requestHandler interface {
+	provider.CommonRequestHandler
+
+	HandleRerank(context *gin.Context)
+}
+
+var rerankHandlers = []requestHandler{
+	&jinaProvider{},
+	&dashScopeProvider{},
+	&cohereProvider{},
+}
+
+func HandleRerank(context *gin.Context) {
+	for _, handler := range rerankHandlers {
+		if handler.ShouldHandleRequest(context) {
+			handler.HandleRerank(context)
+			return
+		}
+	}
+	context.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+}
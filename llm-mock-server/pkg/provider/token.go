@@ -0,0 +1,46 @@
+package provider
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// AccessTokenStore keeps the short-lived access tokens issued by the mock token endpoints in memory.
+type AccessTokenStore struct {
+	mutex  sync.Mutex
+	tokens map[string]time.Time
+}
+
+func NewAccessTokenStore() *AccessTokenStore {
+	return &AccessTokenStore{tokens: map[string]time.Time{}}
+}
+
+// Issue creates a new random token with the given prefix, which expires after ttl.
+func (s *AccessTokenStore) Issue(prefix string, ttl time.Duration) string {
+	token := RandomToken(prefix)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.tokens[token] = time.Now().Add(ttl)
+	return token
+}
+
+// Lookup reports whether the token was issued by the store, and if so, whether it has expired.
+func (s *AccessTokenStore) Lookup(token string) (found bool, expired bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	expiresAt, found := s.tokens[token]
+	if !found {
+		return false, false
+	}
+	return true, time.Now().After(expiresAt)
+}
+
+// RandomToken returns a random hex string with the given prefix.
+func RandomToken(prefix string) string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return prefix + hex.EncodeToString(buf)
+}